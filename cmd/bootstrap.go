@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"github.com/go-api-template/go-backend/models"
+	"github.com/go-api-template/go-backend/services"
+	"github.com/spf13/cobra"
+	"net/mail"
+)
+
+type bootstrapOptions struct {
+	Email    string
+	Password string
+}
+
+func newBootstrapCmd() *cobra.Command {
+
+	// Command options
+	o := bootstrapOptions{}
+
+	// Command
+	bootstrapCmd := &cobra.Command{
+		Use:     "bootstrap",
+		GroupID: "user",
+		Short:   "Create the first platform admin",
+		Long: "Create the first platform admin with a verified email address.\n" +
+			"It fails if a platform admin already exists.\n" +
+			"The password is prompted if it is not given as a flag.",
+		Args: cobra.NoArgs,
+		RunE: o.bootstrapCmd,
+	}
+
+	// Flags
+	bootstrapCmd.Flags().StringVarP(&o.Email, "email", "e", "", "email address of the admin")
+	bootstrapCmd.Flags().StringVarP(&o.Password, "password", "p", "", "password of the admin")
+	_ = bootstrapCmd.MarkFlagRequired("email")
+
+	// Silence usage when an error occurs
+	bootstrapCmd.SilenceUsage = true
+
+	return bootstrapCmd
+}
+
+func (o *bootstrapOptions) bootstrapCmd(cmd *cobra.Command, _ []string) error {
+	// Validate the email address
+	if _, err := mail.ParseAddress(o.Email); err != nil {
+		return fmt.Errorf("invalid email address: %w", err)
+	}
+
+	// Connect to the database
+	ctx := context.Background()
+	gormDb, closeDb := openDatabase(ctx)
+	defer closeDb()
+	userService := services.NewUserService(ctx, gormDb, nil)
+
+	// The platform can only be bootstrapped once
+	// It is checked before prompting the password, and again when the admin is created
+	admins, err := userService.CountByRole(models.RoleAdmin)
+	if err != nil {
+		return err
+	}
+	if admins > 0 {
+		return services.ErrAdminExists
+	}
+
+	// Get the password
+	if o.Password == "" {
+		if o.Password, err = readNewPassword(); err != nil {
+			return err
+		}
+	}
+	if err := checkPassword(o.Password); err != nil {
+		return err
+	}
+
+	// Create the admin
+	user, err := userService.CreateFirstAdmin(&models.UserSignUp{
+		Email:                o.Email,
+		Password:             o.Password,
+		PasswordConfirmation: o.Password,
+	})
+	if err != nil {
+		return err
+	}
+
+	cmd.Printf("Platform admin %s created\n", user.Email)
+	return nil
+}
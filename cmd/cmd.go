@@ -10,7 +10,11 @@ import (
 
 // Options stores the options which are used in the command
 type options struct {
-	RunCmd *cobra.Command
+	RunCmd       *cobra.Command
+	BootstrapCmd *cobra.Command
+	UserCmd      *cobra.Command
+	MigrateCmd   *cobra.Command
+	DbCmd        *cobra.Command
 }
 
 // This is the main command of the application
@@ -19,7 +23,11 @@ func newCmd() *cobra.Command {
 
 	// Command options
 	o := options{
-		RunCmd: newRunCmd(),
+		RunCmd:       newRunCmd(),
+		BootstrapCmd: newBootstrapCmd(),
+		UserCmd:      newUserCmd(),
+		MigrateCmd:   newMigrateCmd(),
+		DbCmd:        newDbCmd(),
 	}
 
 	// Create the main command
@@ -32,6 +40,12 @@ func newCmd() *cobra.Command {
 	// Sub commands
 	mainCmd.AddGroup(&cobra.Group{ID: "server", Title: color.HiGreen.Sprint("Server:")})
 	mainCmd.AddCommand(o.RunCmd)
+	mainCmd.AddGroup(&cobra.Group{ID: "user", Title: color.HiGreen.Sprint("Users:")})
+	mainCmd.AddCommand(o.BootstrapCmd)
+	mainCmd.AddCommand(o.UserCmd)
+	mainCmd.AddGroup(&cobra.Group{ID: "database", Title: color.HiGreen.Sprint("Database:")})
+	mainCmd.AddCommand(o.MigrateCmd)
+	mainCmd.AddCommand(o.DbCmd)
 
 	return mainCmd
 }
@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/go-api-template/go-backend/models"
+	"github.com/go-api-template/go-backend/modules/cache"
+	"github.com/go-api-template/go-backend/modules/config"
+	postgres_db "github.com/go-api-template/go-backend/modules/database/postgres"
+	"github.com/go-api-template/go-backend/services"
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
+)
+
+// openDatabase connects to the configured database
+// The returned function must be called to close the connection
+func openDatabase(ctx context.Context) (*gorm.DB, func()) {
+	gormDb, sqlDb := postgres_db.NewPostgres(ctx)
+	return gormDb, func() { _ = sqlDb.Close() }
+}
+
+// openCache connects to the cache, so the users modified by a command are removed from it
+// The commands still run when Redis is unavailable, the cached users then expire later
+// The returned function must be called to close the connection
+func openCache(ctx context.Context) (*cache.Cache, func()) {
+	rc := redis.NewClient(&redis.Options{
+		Addr: fmt.Sprintf("%s:%s", config.Config.Redis.Host, config.Config.Redis.Port),
+	})
+	if err := rc.Ping(ctx).Err(); err != nil {
+		log.Warn().Err(err).Msg("Pinging Redis instance, the cache is not updated")
+		_ = rc.Close()
+		return nil, func() {}
+	}
+	return cache.NewCache(rc), func() { _ = rc.Close() }
+}
+
+// findUser finds a user from its id or from its email address
+func findUser(userService services.UserService, ref string) (*models.User, error) {
+	var user *models.User
+	var err error
+
+	if id, parseErr := uuid.Parse(ref); parseErr == nil {
+		user, err = userService.FindById(id)
+	} else {
+		user, err = userService.FindByEmail(ref)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, errors.New("unknown user " + ref)
+	}
+
+	return user, nil
+}
@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"context"
+	"github.com/go-api-template/go-backend/modules/config"
+	"github.com/go-api-template/go-backend/modules/database/seeds"
+	"github.com/spf13/cobra"
+	"strings"
+)
+
+type dbOptions struct {
+	// seed options
+	Env  string
+	Only []string
+}
+
+func newDbCmd() *cobra.Command {
+
+	// Command options
+	o := &dbOptions{}
+
+	// Command
+	dbCmd := &cobra.Command{
+		Use:     "db",
+		GroupID: "database",
+		Short:   "Manage the data of the database",
+	}
+
+	// Seed the database
+	seedCmd := &cobra.Command{
+		Use:   "seed",
+		Short: "Fill the database with the seeders of an environnement",
+		Long: "Fill the database with the seeders registered for an environnement.\n" +
+			"The seeders are idempotent, they can be run several times.\n" +
+			"The migrations must be applied first.",
+		Args: cobra.NoArgs,
+		RunE: o.seedCmd,
+	}
+	seedCmd.Flags().StringVarP(&o.Env, "env", "e", config.Config.App.Environnement.String(),
+		"environnement of the seeders (development, staging or production)")
+	seedCmd.Flags().StringSliceVarP(&o.Only, "only", "o", nil, "names of the seeders to run, all by default")
+
+	// Sub commands
+	dbCmd.AddCommand(seedCmd)
+
+	// Silence usage when an error occurs
+	for _, c := range dbCmd.Commands() {
+		c.SilenceUsage = true
+	}
+
+	return dbCmd
+}
+
+func (o *dbOptions) seedCmd(cmd *cobra.Command, _ []string) error {
+	env := config.Environnement(strings.ToLower(o.Env))
+	if len(seeds.Seeders(env)) == 0 {
+		cmd.Printf("No seeder registered for the %s environnement\n", o.Env)
+		return nil
+	}
+
+	// Connect to the database
+	ctx := context.Background()
+	gormDb, closeDb := openDatabase(ctx)
+	defer closeDb()
+
+	ran, err := seeds.Run(ctx, gormDb, env, o.Only)
+	for _, seeder := range ran {
+		cmd.Printf("Seeded %s: %s\n", seeder.Name, seeder.Description)
+	}
+	return err
+}
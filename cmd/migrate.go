@@ -0,0 +1,190 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"github.com/go-api-template/go-backend/modules/database/migrations"
+	"github.com/spf13/cobra"
+	"strconv"
+	"text/tabwriter"
+	"time"
+)
+
+type migrateOptions struct {
+	// create options
+	Dir string
+}
+
+func newMigrateCmd() *cobra.Command {
+
+	// Command options
+	o := &migrateOptions{}
+
+	// Command
+	migrateCmd := &cobra.Command{
+		Use:     "migrate",
+		GroupID: "database",
+		Short:   "Manage the database migrations",
+		Long: "Manage the versioned migrations of the configured database.\n" +
+			"The migrations are embedded in the binary and the applied ones\n" +
+			"are tracked in the schema_migrations table.",
+	}
+
+	// Apply the pending migrations
+	upCmd := &cobra.Command{
+		Use:   "up [n]",
+		Short: "Apply the n next pending migrations, or all of them",
+		Args:  cobra.MaximumNArgs(1),
+		RunE:  o.upCmd,
+	}
+
+	// Revert the applied migrations
+	downCmd := &cobra.Command{
+		Use:   "down [n]",
+		Short: "Revert the n last applied migrations, or the last one",
+		Args:  cobra.MaximumNArgs(1),
+		RunE:  o.downCmd,
+	}
+
+	// Show the status of the migrations
+	statusCmd := &cobra.Command{
+		Use:   "status",
+		Short: "Show the applied and pending migrations",
+		Args:  cobra.NoArgs,
+		RunE:  o.statusCmd,
+	}
+
+	// Create a migration
+	createCmd := &cobra.Command{
+		Use:   "create <name>",
+		Short: "Create the up and down files of a new migration",
+		Long: "Create the up and down files of a new migration.\n" +
+			"The binary must be rebuilt to embed the new migration.",
+		Args: cobra.ExactArgs(1),
+		RunE: o.createCmd,
+	}
+	createCmd.Flags().StringVarP(&o.Dir, "dir", "d", migrations.Dir, "directory of the migration files")
+
+	// Sub commands
+	migrateCmd.AddCommand(upCmd, downCmd, statusCmd, createCmd)
+
+	// Silence usage when an error occurs
+	for _, c := range migrateCmd.Commands() {
+		c.SilenceUsage = true
+	}
+
+	return migrateCmd
+}
+
+func (o *migrateOptions) upCmd(cmd *cobra.Command, args []string) error {
+	n, err := migrationCount(args)
+	if err != nil {
+		return err
+	}
+
+	migrator, closeDb, err := openMigrator()
+	if err != nil {
+		return err
+	}
+	defer closeDb()
+
+	applied, err := migrator.Up(n)
+	for _, migration := range applied {
+		cmd.Printf("Applied %d_%s\n", migration.Version, migration.Name)
+	}
+	if err != nil {
+		return err
+	}
+	if len(applied) == 0 {
+		cmd.Println("No pending migration")
+	}
+
+	return nil
+}
+
+func (o *migrateOptions) downCmd(cmd *cobra.Command, args []string) error {
+	n, err := migrationCount(args)
+	if err != nil {
+		return err
+	}
+
+	migrator, closeDb, err := openMigrator()
+	if err != nil {
+		return err
+	}
+	defer closeDb()
+
+	reverted, err := migrator.Down(n)
+	for _, migration := range reverted {
+		cmd.Printf("Reverted %d_%s\n", migration.Version, migration.Name)
+	}
+	if err != nil {
+		return err
+	}
+	if len(reverted) == 0 {
+		cmd.Println("No applied migration")
+	}
+
+	return nil
+}
+
+func (o *migrateOptions) statusCmd(cmd *cobra.Command, _ []string) error {
+	migrator, closeDb, err := openMigrator()
+	if err != nil {
+		return err
+	}
+	defer closeDb()
+
+	all, err := migrator.Status()
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT")
+	for _, migration := range all {
+		appliedAt := "pending"
+		if migration.IsApplied() {
+			appliedAt = migration.AppliedAt.Format(time.RFC3339)
+		}
+		_, _ = fmt.Fprintf(w, "%d\t%s\t%s\n", migration.Version, migration.Name, appliedAt)
+	}
+
+	return w.Flush()
+}
+
+func (o *migrateOptions) createCmd(cmd *cobra.Command, args []string) error {
+	up, down, err := migrations.Create(o.Dir, args[0])
+	if err != nil {
+		return err
+	}
+
+	cmd.Printf("Created %s\n", up)
+	cmd.Printf("Created %s\n", down)
+	return nil
+}
+
+// openMigrator connects to the configured database and creates a migrator
+// The returned function must be called to close the connection
+func openMigrator() (*migrations.Migrator, func(), error) {
+	ctx := context.Background()
+	gormDb, closeDb := openDatabase(ctx)
+	sqlDb, err := gormDb.DB()
+	if err != nil {
+		closeDb()
+		return nil, nil, err
+	}
+	return migrations.NewMigrator(ctx, sqlDb), closeDb, nil
+}
+
+// migrationCount parses the optional number of migrations given as argument
+func migrationCount(args []string) (int, error) {
+	if len(args) == 0 {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(args[0])
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid number of migrations %s", args[0])
+	}
+	return n, nil
+}
@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"golang.org/x/term"
+	"os"
+	"strings"
+)
+
+// readPassword reads a password from the terminal without echoing it
+// If the standard input is not a terminal, the password is read from the first line
+func readPassword(prompt string) (string, error) {
+	fd := int(os.Stdin.Fd())
+
+	// Read the password from a pipe
+	if !term.IsTerminal(fd) {
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && line == "" {
+			return "", errors.New("cannot read the password from the standard input")
+		}
+		return strings.TrimRight(line, "\r\n"), nil
+	}
+
+	// Read the password from the terminal
+	fmt.Print(prompt)
+	password, err := term.ReadPassword(fd)
+	fmt.Println()
+	if err != nil {
+		return "", err
+	}
+	return string(password), nil
+}
+
+// readNewPassword reads a new password and its confirmation
+func readNewPassword() (string, error) {
+	password, err := readPassword("Password: ")
+	if err != nil {
+		return "", err
+	}
+	if err := checkPassword(password); err != nil {
+		return "", err
+	}
+
+	// Confirm the password when it is typed in a terminal
+	if term.IsTerminal(int(os.Stdin.Fd())) {
+		confirmation, err := readPassword("Confirm password: ")
+		if err != nil {
+			return "", err
+		}
+		if password != confirmation {
+			return "", errors.New("passwords do not match")
+		}
+	}
+
+	return password, nil
+}
+
+// checkPassword checks that a new password, typed or given as a flag, is long enough
+func checkPassword(password string) error {
+	if len(password) < 8 {
+		return errors.New("the password must contain at least 8 characters")
+	}
+	return nil
+}
@@ -0,0 +1,280 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/go-api-template/go-backend/models"
+	"github.com/go-api-template/go-backend/modules/utils"
+	api "github.com/go-api-template/go-backend/modules/utils/api"
+	"github.com/go-api-template/go-backend/services"
+	"github.com/go-mods/convert"
+	"github.com/spf13/cobra"
+	"net/mail"
+	"os"
+	"strings"
+	"text/tabwriter"
+)
+
+type userOptions struct {
+	// create options
+	Admin    bool
+	Password string
+
+	// list options
+	Limit int
+
+	// delete options
+	Yes bool
+
+	// userService is initialized before running a sub command
+	userService services.UserService
+	closeDb     func()
+}
+
+func newUserCmd() *cobra.Command {
+
+	// Command options
+	o := &userOptions{}
+
+	// Command
+	userCmd := &cobra.Command{
+		Use:     "user",
+		GroupID: "user",
+		Short:   "Manage the users",
+		Long: "Manage the users of the configured database.\n" +
+			"A user can be referenced by its id or by its email address.",
+		PersistentPreRunE:  o.open,
+		PersistentPostRunE: o.close,
+	}
+
+	// Create a user
+	createCmd := &cobra.Command{
+		Use:   "create <email>",
+		Short: "Create a user with a verified email address",
+		Args:  cobra.ExactArgs(1),
+		RunE:  o.createCmd,
+	}
+	createCmd.Flags().BoolVar(&o.Admin, "admin", false, "give the platform admin role to the user")
+	createCmd.Flags().StringVarP(&o.Password, "password", "p", "", "password of the user, it is prompted if empty")
+
+	// List the users
+	listCmd := &cobra.Command{
+		Use:     "list",
+		Aliases: []string{"ls"},
+		Short:   "List the users",
+		Args:    cobra.NoArgs,
+		RunE:    o.listCmd,
+	}
+	listCmd.Flags().IntVarP(&o.Limit, "limit", "l", 100, "maximum number of users to list")
+
+	// Set the role of a user
+	setRoleCmd := &cobra.Command{
+		Use:       "set-role <user> <role>",
+		Short:     "Set the platform role of a user",
+		Args:      cobra.ExactArgs(2),
+		ValidArgs: []string{models.RoleAdmin.String(), models.RoleUser.String()},
+		RunE:      o.setRoleCmd,
+	}
+
+	// Verify the email address of a user
+	verifyCmd := &cobra.Command{
+		Use:   "verify <user>",
+		Short: "Mark the email address of a user as verified",
+		Args:  cobra.ExactArgs(1),
+		RunE:  o.verifyCmd,
+	}
+
+	// Reset the password of a user
+	resetPasswordCmd := &cobra.Command{
+		Use:   "reset-password <user>",
+		Short: "Reset the password of a user",
+		Args:  cobra.ExactArgs(1),
+		RunE:  o.resetPasswordCmd,
+	}
+	resetPasswordCmd.Flags().StringVarP(&o.Password, "password", "p", "", "new password of the user, it is prompted if empty")
+
+	// Delete a user
+	deleteCmd := &cobra.Command{
+		Use:     "delete <user>",
+		Aliases: []string{"rm"},
+		Short:   "Anonymize and delete a user",
+		Args:    cobra.ExactArgs(1),
+		RunE:    o.deleteCmd,
+	}
+	deleteCmd.Flags().BoolVarP(&o.Yes, "yes", "y", false, "do not ask for confirmation")
+
+	// Sub commands
+	userCmd.AddCommand(createCmd, listCmd, setRoleCmd, verifyCmd, resetPasswordCmd, deleteCmd)
+
+	// Silence usage when an error occurs
+	for _, c := range userCmd.Commands() {
+		c.SilenceUsage = true
+	}
+
+	return userCmd
+}
+
+// open connects to the database before running a sub command
+func (o *userOptions) open(_ *cobra.Command, _ []string) error {
+	ctx := context.Background()
+	gormDb, closeDb := openDatabase(ctx)
+	userCache, closeCache := openCache(ctx)
+	o.userService = services.NewUserService(ctx, gormDb, userCache)
+	o.closeDb = func() {
+		closeCache()
+		closeDb()
+	}
+	return nil
+}
+
+// close closes the database connection after running a sub command
+func (o *userOptions) close(_ *cobra.Command, _ []string) error {
+	if o.closeDb != nil {
+		o.closeDb()
+	}
+	return nil
+}
+
+func (o *userOptions) createCmd(cmd *cobra.Command, args []string) (err error) {
+	email := args[0]
+	if _, err := mail.ParseAddress(email); err != nil {
+		return fmt.Errorf("invalid email address: %w", err)
+	}
+
+	// Get the password
+	if o.Password == "" {
+		if o.Password, err = readNewPassword(); err != nil {
+			return err
+		}
+	}
+	if err := checkPassword(o.Password); err != nil {
+		return err
+	}
+
+	// Create the user
+	user, err := o.userService.CreateVerified(&models.UserSignUp{
+		Email:                email,
+		Password:             o.Password,
+		PasswordConfirmation: o.Password,
+	})
+	if err != nil {
+		return err
+	}
+
+	// Give the admin role
+	if o.Admin {
+		user.Role = models.RoleAdmin
+		if user, err = o.userService.Update(user.ID, user); err != nil {
+			return err
+		}
+	}
+
+	cmd.Printf("User %s created with id %s and role %s\n", user.Email, user.ID, user.Role)
+	return nil
+}
+
+func (o *userOptions) listCmd(cmd *cobra.Command, _ []string) error {
+	users, _, err := o.userService.FindAll(api.Filter{
+		Limit: convert.ToPtr(o.Limit),
+		Sort:  []api.Sort{{Column: "users.created_at"}},
+	}, api.Selection{})
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "ID\tEMAIL\tNAME\tROLE\tVERIFIED")
+	for _, user := range users {
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%t\n", user.ID, user.Email, user.Name, user.Role, user.Verified)
+	}
+	return w.Flush()
+}
+
+func (o *userOptions) setRoleCmd(cmd *cobra.Command, args []string) error {
+	role, err := models.ParseRole(args[1])
+	if err != nil {
+		return err
+	}
+
+	user, err := findUser(o.userService, args[0])
+	if err != nil {
+		return err
+	}
+
+	user.Role = role
+	if _, err := o.userService.Update(user.ID, user); err != nil {
+		return err
+	}
+
+	cmd.Printf("User %s has now the role %s\n", user.Email, role)
+	return nil
+}
+
+func (o *userOptions) verifyCmd(cmd *cobra.Command, args []string) error {
+	user, err := findUser(o.userService, args[0])
+	if err != nil {
+		return err
+	}
+
+	user.Verified = true
+	if _, err := o.userService.Update(user.ID, user); err != nil {
+		return err
+	}
+
+	cmd.Printf("User %s is now verified\n", user.Email)
+	return nil
+}
+
+func (o *userOptions) resetPasswordCmd(cmd *cobra.Command, args []string) (err error) {
+	user, err := findUser(o.userService, args[0])
+	if err != nil {
+		return err
+	}
+
+	// Get the new password
+	if o.Password == "" {
+		if o.Password, err = readNewPassword(); err != nil {
+			return err
+		}
+	}
+	if err := checkPassword(o.Password); err != nil {
+		return err
+	}
+
+	// Hash the new password and clear any pending reset token
+	if user.Password, err = utils.HashPassword(o.Password); err != nil {
+		return err
+	}
+	user.SetResetToken("")
+	if _, err := o.userService.Update(user.ID, user); err != nil {
+		return err
+	}
+
+	cmd.Printf("Password of user %s has been reset\n", user.Email)
+	return nil
+}
+
+func (o *userOptions) deleteCmd(cmd *cobra.Command, args []string) error {
+	user, err := findUser(o.userService, args[0])
+	if err != nil {
+		return err
+	}
+
+	// Ask for confirmation
+	if !o.Yes {
+		cmd.Printf("Delete user %s (%s)? [y/N] ", user.Email, user.ID)
+		answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+		if a := strings.ToLower(strings.TrimSpace(answer)); a != "y" && a != "yes" {
+			return errors.New("deletion cancelled")
+		}
+	}
+
+	if err := o.userService.Delete(user.ID); err != nil {
+		return err
+	}
+
+	cmd.Printf("User %s deleted\n", user.Email)
+	return nil
+}
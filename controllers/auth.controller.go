@@ -1,12 +1,12 @@
 package controllers
 
 import (
-	"bytes"
-	"errors"
 	"fmt"
 	"github.com/gin-gonic/gin"
 	"github.com/go-api-template/go-backend/models"
 	"github.com/go-api-template/go-backend/modules/config"
+	"github.com/go-api-template/go-backend/modules/i18n"
+	"github.com/go-api-template/go-backend/modules/metrics"
 	"github.com/go-api-template/go-backend/modules/middlewares"
 	"github.com/go-api-template/go-backend/modules/utils"
 	api "github.com/go-api-template/go-backend/modules/utils/api"
@@ -14,8 +14,6 @@ import (
 	"github.com/go-api-template/go-backend/services"
 	"github.com/google/uuid"
 	"github.com/rs/zerolog/log"
-	"io"
-	"net/http"
 	"strings"
 	"time"
 )
@@ -37,8 +35,9 @@ type AuthController interface {
 // AuthControllerImpl is the controller for authentification
 // It implements the AuthController interface
 type AuthControllerImpl struct {
-	userService   services.UserService
-	mailerService services.MailerService
+	userService         services.UserService
+	mailerService       services.MailerService
+	registrationService services.RegistrationService
 }
 
 // AuthControllerImpl implements the AuthController interface
@@ -50,61 +49,64 @@ var (
 	CtxLoggedIn     = "logged_in"
 )
 
-func NewAuthController(userService services.UserService, mailerService services.MailerService) AuthController {
-	return &AuthControllerImpl{userService: userService, mailerService: mailerService}
+func NewAuthController(userService services.UserService, mailerService services.MailerService, registrationService services.RegistrationService) AuthController {
+	return &AuthControllerImpl{userService: userService, mailerService: mailerService, registrationService: registrationService}
 }
 
 // SignUp godoc
 //
 //	@Summary		Create a new user
-//	@Description	Create a new user. The first user created is an admin.
+//	@Description	Create a new user. The registration policy defines who can sign up.
 //	@Tags			auth
 //	@Accept			json
 //	@Produce		json
 //	@Param			user	body		models.UserSignUp	true	"User sign up"
 //	@Success		201		{object}	models.User
-//	@Failure		400		{object}	api.Error
-//	@Failure		409		{object}	api.Error
-//	@Failure		412		{object}	api.Error
-//	@Failure		502		{object}	api.Error
+//	@Failure		400		{object}	api.Problem
+//	@Failure		403		{object}	api.Problem
+//	@Failure		409		{object}	api.Problem
+//	@Failure		502		{object}	api.Problem
 //	@Router			/auth/signup [post]
 func (c *AuthControllerImpl) SignUp(ctx *gin.Context) {
 	var payload *models.UserSignUp
 
 	// Bind the request body to the payload
-	if err := ctx.ShouldBindJSON(&payload); err != nil {
-		api.Ctx(ctx).BadRequest().WithError(err).Send()
+	if !api.Bind(ctx, &payload) {
 		return
 	}
 
-	// Check if the passwords match
-	if payload.Password != payload.PasswordConfirmation {
-		api.Ctx(ctx).PreconditionFailed().
-			WithCode("password_match").
-			WithDescription("passwords do not match").
+	// The user speaks the language of the request if no locale is chosen
+	if payload.Locale == "" {
+		payload.Locale = i18n.Locale(ctx)
+	}
+
+	// Check the registration policy
+	if err := c.registrationService.CanSignUp(payload.Email); err != nil {
+		api.Ctx(ctx).Forbidden().
+			WithCode(api.CodeRegistrationDenied).
+			WithError(err).
 			Send()
 		return
 	}
 
 	// Sign up the user
-	user, err := c.userService.Create(payload)
+	user, err := c.userService.WithContext(ctx.Request.Context()).Create(payload)
 	if err != nil {
 		if strings.Contains(err.Error(), "email already exist") {
-			api.Ctx(ctx).Conflict().WithError(err).Send()
+			api.Ctx(ctx).Conflict().WithCode(api.CodeAccountExists).WithError(err).Send()
 			return
 		}
 		api.Ctx(ctx).BadGateway().WithError(err).Send()
 		return
 	}
+	metrics.SignUps.WithLabelValues("form").Inc()
 
 	// Send verification code to user email address in background
-	go func() {
-		if user.VerificationToken != "" {
-			if err := c.mailerService.SendVerificationToken(user); err != nil {
-				log.Error().Err(err).Msg("Failed to send verification code")
-			}
+	if c.mailerService != nil && user.VerificationToken != "" {
+		if err := c.mailerService.SendVerificationToken(ctx.Request.Context(), user); err != nil {
+			log.Ctx(ctx.Request.Context()).Error().Err(err).Msg("Failed to send verification code")
 		}
-	}()
+	}
 
 	// Send the response
 	api.Ctx(ctx).Created().SendRaw(user.Response())
@@ -119,26 +121,26 @@ func (c *AuthControllerImpl) SignUp(ctx *gin.Context) {
 //	@Produce		json
 //	@Param			account	body		models.UserSignIn	true	"User credential"
 //	@Success		201		{object}	token.AccessToken
-//	@Failure		400		{object}	api.Error
-//	@Failure		404		{object}	api.Error
+//	@Failure		400		{object}	api.Problem
+//	@Failure		404		{object}	api.Problem
 //	@Router			/auth/signin [post]
 func (c *AuthControllerImpl) SignIn(ctx *gin.Context) {
 	var payload *models.UserSignIn
 
-	if err := ctx.ShouldBindJSON(&payload); err != nil {
-		api.Ctx(ctx).BadRequest().WithError(err).Send()
+	if !api.Bind(ctx, &payload) {
 		return
 	}
 
 	// Find the user by email
-	user, err := c.userService.FindByEmail(payload.Email)
+	user, err := c.userService.WithContext(ctx.Request.Context()).FindByEmail(payload.Email)
 	if err != nil {
 		api.Ctx(ctx).BadRequest().WithError(err).Send()
 		return
 	}
 	if user == nil {
+		metrics.SignIns.WithLabelValues("invalid_credentials").Inc()
 		api.Ctx(ctx).NotFound().
-			WithCode("invalid").
+			WithCode(api.CodeInvalidCredentials).
 			WithDescription("Invalid email or password").
 			Send()
 		return
@@ -146,22 +148,25 @@ func (c *AuthControllerImpl) SignIn(ctx *gin.Context) {
 
 	// Check if the password is correct
 	if err := utils.VerifyPassword(user.Password, payload.Password); err != nil {
+		metrics.SignIns.WithLabelValues("invalid_credentials").Inc()
 		api.Ctx(ctx).BadRequest().
-			WithCode("invalid").
+			WithCode(api.CodeInvalidCredentials).
 			WithDescription("Invalid email or password").
 			Send()
 		return
 	}
 	// Check if the account is verified
 	if !user.Verified {
+		metrics.SignIns.WithLabelValues("not_verified").Inc()
 		api.Ctx(ctx).BadRequest().
-			WithCode("invalid").
+			WithCode(api.CodeAccountNotVerified).
 			WithDescription("Account not verified").
 			Send()
 		return
 	}
 
 	// Sign in the user
+	metrics.SignIns.WithLabelValues("success").Inc()
 	c.signin(ctx, user)
 }
 
@@ -191,14 +196,14 @@ func (c *AuthControllerImpl) signin(ctx *gin.Context, user *models.User) {
 //	@Accept			json
 //	@Produce		json
 //	@Success		200	{object}	api.Success
-//	@Failure		401	{object}	api.Error
+//	@Failure		401	{object}	api.Problem
 //	@Router			/auth/signout [get]
 func (c *AuthControllerImpl) SignOut(ctx *gin.Context) {
 	// Get the user from the context
 	user, err := middlewares.GetUserFromContext(ctx)
 	if err != nil || user == nil {
 		api.Ctx(ctx).Unauthorized().
-			WithCode("invalid").
+			WithCode(api.CodeNotAuthenticated).
 			WithDescription("The user must be logged in").
 			Send()
 		ctx.Abort()
@@ -228,19 +233,18 @@ func (c *AuthControllerImpl) signOut(ctx *gin.Context, _ *models.User) {
 //	@Produce		json
 //	@Param			email	body		models.UserEmail	true	"User email"	Format(email)
 //	@Success		201		{object}	models.User
-//	@Failure		400		{object}	api.Error
-//	@Failure		403		{object}	api.Error
-//	@Failure		404		{object}	api.Error
+//	@Failure		400		{object}	api.Problem
+//	@Failure		403		{object}	api.Problem
+//	@Failure		404		{object}	api.Problem
 //	@Router			/auth/welcome [post]
 func (c *AuthControllerImpl) Welcome(ctx *gin.Context) {
 	var payload models.UserEmail
-	if err := ctx.ShouldBindJSON(&payload); err != nil {
-		api.Ctx(ctx).BadRequest().WithError(err).Send()
+	if !api.Bind(ctx, &payload) {
 		return
 	}
 
 	// Get the user from the database by email
-	user, err := c.userService.FindByEmail(payload.Email)
+	user, err := c.userService.WithContext(ctx.Request.Context()).FindByEmail(payload.Email)
 	if err != nil {
 		api.Ctx(ctx).BadRequest().WithError(err).Send()
 		return
@@ -248,14 +252,14 @@ func (c *AuthControllerImpl) Welcome(ctx *gin.Context) {
 	if user == nil {
 		message := "unknown user"
 		api.Ctx(ctx).NotFound().
-			WithCode("unknown_user").
+			WithCode(api.CodeUserNotFound).
 			WithDescription(message).
 			Send()
 		return
 	}
 	if user.Verified {
 		api.Ctx(ctx).Forbidden().
-			WithCode("account_verified").
+			WithCode(api.CodeAccountAlreadyVerified).
 			WithDescription("Account already verified").
 			Send()
 		return
@@ -265,17 +269,17 @@ func (c *AuthControllerImpl) Welcome(ctx *gin.Context) {
 	user.VerificationToken = utils.Encode(utils.GenerateRandomString(32))
 
 	// Update the user
-	if _, err := c.userService.Update(user.ID, user); err != nil {
+	if _, err := c.userService.WithContext(ctx.Request.Context()).Update(user.ID, user); err != nil {
 		api.Ctx(ctx).BadRequest().WithError(err).Send()
 		return
 	}
 
 	// Send verification code to user email address in background
-	go func() {
-		if err := c.mailerService.SendVerificationToken(user); err != nil {
-			log.Error().Err(err).Msg("Failed to send the verification token")
+	if c.mailerService != nil {
+		if err := c.mailerService.SendVerificationToken(ctx.Request.Context(), user); err != nil {
+			log.Ctx(ctx.Request.Context()).Error().Err(err).Msg("Failed to send the verification token")
 		}
-	}()
+	}
 
 	// Send the response
 	api.Ctx(ctx).Created().SendRaw(user.Response())
@@ -290,15 +294,15 @@ func (c *AuthControllerImpl) Welcome(ctx *gin.Context) {
 //	@Produce		json
 //	@Param			token	path		string	true	"verification code sent by email"
 //	@Success		200		{object}	api.Success
-//	@Failure		400		{object}	api.Error
-//	@Failure		404		{object}	api.Error
+//	@Failure		400		{object}	api.Problem
+//	@Failure		404		{object}	api.Problem
 //	@Router			/auth/verify/{token} [get]
 func (c *AuthControllerImpl) VerifyEmail(ctx *gin.Context) {
 	// Get the verification code passed in the url
 	verificationToken := ctx.Params.ByName("token")
 
 	// Get the user with the verification code
-	user, err := c.userService.FindByVerificationToken(verificationToken)
+	user, err := c.userService.WithContext(ctx.Request.Context()).FindByVerificationToken(verificationToken)
 	if err != nil {
 		api.Ctx(ctx).BadRequest().WithError(err).Send()
 		return
@@ -306,7 +310,7 @@ func (c *AuthControllerImpl) VerifyEmail(ctx *gin.Context) {
 	if user == nil {
 		message := "the user belonging to this code no longer exists verificationToken " + verificationToken
 		api.Ctx(ctx).NotFound().
-			WithCode("account_error").
+			WithCode(api.CodeInvalidToken).
 			WithDescription(message).
 			Send()
 		return
@@ -316,7 +320,7 @@ func (c *AuthControllerImpl) VerifyEmail(ctx *gin.Context) {
 	user.Verified = true
 
 	// Update the user
-	if _, err := c.userService.Update(user.ID, user); err != nil {
+	if _, err := c.userService.WithContext(ctx.Request.Context()).Update(user.ID, user); err != nil {
 		api.Ctx(ctx).BadRequest().WithError(err).Send()
 		return
 	}
@@ -336,30 +340,22 @@ func (c *AuthControllerImpl) VerifyEmail(ctx *gin.Context) {
 //	@Produce		json
 //	@Param			token	body		models.UserToken	true	"Refresh token"
 //	@Success		201		{object}	token.AccessToken
-//	@Failure		400		{object}	api.Error
-//	@Failure		403		{object}	api.Error
-//	@Failure		404		{object}	api.Error
+//	@Failure		400		{object}	api.Problem
+//	@Failure		403		{object}	api.Problem
+//	@Failure		404		{object}	api.Problem
 //	@Router			/auth/refresh [post]
 func (c *AuthControllerImpl) RefreshTokens(ctx *gin.Context) {
 	var payload *models.UserToken
 	var refreshToken string
 
-	body, _ := io.ReadAll(ctx.Request.Body)
-	println(string(body))
-	ctx.Request.Body = io.NopCloser(bytes.NewReader(body))
-
-	// Get the refresh token from the body
-	if errBind := ctx.ShouldBindJSON(&payload); errBind != nil {
-		// If the body is empty, get the refresh token from the cookie
-		ctxToken, errCtx := ctx.Cookie(CtxRefreshToken)
-		if ctxToken != "" {
-			refreshToken = ctxToken
-		} else if errors.Is(errCtx, http.ErrNoCookie) {
-			api.Ctx(ctx).BadRequest().WithError(errBind).WithError(errCtx).Send()
-			return
-		}
-	} else {
+	// Get the refresh token from the body, or from the cookie if the body is not valid
+	if errBind := ctx.ShouldBindJSON(&payload); errBind == nil {
 		refreshToken = payload.Token
+	} else if cookie, errCookie := ctx.Cookie(CtxRefreshToken); errCookie == nil && cookie != "" {
+		refreshToken = cookie
+	} else {
+		api.SendBodyError(ctx, errBind)
+		return
 	}
 
 	// Validate the refresh token
@@ -380,21 +376,21 @@ func (c *AuthControllerImpl) RefreshTokens(ctx *gin.Context) {
 	subscriberUUID, err := uuid.Parse(fmt.Sprint(subscriber))
 	if err != nil {
 		api.Ctx(ctx).BadRequest().
-			WithCode("token_error").
+			WithCode(api.CodeInvalidToken).
 			WithDescription("cannot get user id from token").
 			Send()
 		return
 	}
 
 	// Get the user from the database
-	user, err := c.userService.FindById(subscriberUUID)
+	user, err := c.userService.WithContext(ctx.Request.Context()).FindById(subscriberUUID)
 	if err != nil {
 		api.Ctx(ctx).BadRequest().WithError(err).Send()
 		return
 	}
 	if user == nil {
 		api.Ctx(ctx).NotFound().
-			WithCode("token_error").
+			WithCode(api.CodeInvalidToken).
 			WithDescription("the user belonging to this token no longer exists").
 			Send()
 		return
@@ -425,32 +421,31 @@ func (c *AuthControllerImpl) RefreshTokens(ctx *gin.Context) {
 //	@Produce		json
 //	@Param			email	body		models.UserEmail	true	"User email"	Format(email)
 //	@Success		201		{object}	api.Success
-//	@Failure		400		{object}	api.Error
-//	@Failure		401		{object}	api.Error
+//	@Failure		400		{object}	api.Problem
+//	@Failure		401		{object}	api.Problem
 //	@Router			/auth/forgot-password/{email} [post]
 func (c *AuthControllerImpl) ForgotPassword(ctx *gin.Context) {
 	var payload models.UserEmail
-	if err := ctx.ShouldBindJSON(&payload); err != nil {
-		api.Ctx(ctx).BadRequest().WithError(err).Send()
+	if !api.Bind(ctx, &payload) {
 		return
 	}
 
 	// Get the user from the database by email
-	user, err := c.userService.FindByEmail(payload.Email)
+	user, err := c.userService.WithContext(ctx.Request.Context()).FindByEmail(payload.Email)
 	if err != nil {
 		api.Ctx(ctx).BadRequest().WithError(err).Send()
 		return
 	}
 	if user == nil {
 		api.Ctx(ctx).NotFound().
-			WithCode("unknown_user").
+			WithCode(api.CodeUserNotFound).
 			WithDescription("unknown user").
 			Send()
 		return
 	}
 	if !user.Verified {
 		api.Ctx(ctx).Unauthorized().
-			WithCode("not_verified").
+			WithCode(api.CodeAccountNotVerified).
 			WithDescription("Account not verified").
 			Send()
 		return
@@ -460,17 +455,17 @@ func (c *AuthControllerImpl) ForgotPassword(ctx *gin.Context) {
 	user.SetResetToken(utils.Encode(utils.GenerateRandomString(32)))
 
 	// Update the user
-	if _, err := c.userService.Update(user.ID, user); err != nil {
+	if _, err := c.userService.WithContext(ctx.Request.Context()).Update(user.ID, user); err != nil {
 		api.Ctx(ctx).BadRequest().WithError(err).Send()
 		return
 	}
 
 	// Send verification code to user email address in background
-	go func() {
-		if err := c.mailerService.SendResetToken(user); err != nil {
-			log.Error().Err(err).Msg("Failed to send the reset token")
+	if c.mailerService != nil {
+		if err := c.mailerService.SendResetToken(ctx.Request.Context(), user); err != nil {
+			log.Ctx(ctx.Request.Context()).Error().Err(err).Msg("Failed to send the reset token")
 		}
-	}()
+	}
 
 	// Send the response
 	// Add the verification code if the app is in debug mode
@@ -497,11 +492,10 @@ func (c *AuthControllerImpl) ForgotPassword(ctx *gin.Context) {
 //	@Produce		json
 //	@Param			password	body		models.UserPasswordConfirmation	true	"New password with confirmation"
 //	@Success		200			{object}	api.Success
-//	@Failure		400			{object}	api.Error
-//	@Failure		401			{object}	api.Error
-//	@Failure		403			{object}	api.Error
-//	@Failure		404			{object}	api.Error
-//	@Failure		412			{object}	api.Error
+//	@Failure		400			{object}	api.Problem
+//	@Failure		401			{object}	api.Problem
+//	@Failure		403			{object}	api.Problem
+//	@Failure		404			{object}	api.Problem
 //	@Router			/auth/reset-password/{token} [patch]
 func (c *AuthControllerImpl) ResetPassword(ctx *gin.Context) {
 
@@ -510,22 +504,12 @@ func (c *AuthControllerImpl) ResetPassword(ctx *gin.Context) {
 
 	// Get the password from the body
 	var payload *models.UserPasswordConfirmation
-	if err := ctx.ShouldBindJSON(&payload); err != nil {
-		api.Ctx(ctx).BadRequest().WithError(err).Send()
-		return
-	}
-
-	// Check if the passwords match
-	if payload.Password != payload.PasswordConfirmation {
-		api.Ctx(ctx).PreconditionFailed().
-			WithCode("password_error").
-			WithDescription("passwords do not match").
-			Send()
+	if !api.Bind(ctx, &payload) {
 		return
 	}
 
 	// Get the user with the reset token
-	user, err := c.userService.FindByResetPasswordToken(resetToken)
+	user, err := c.userService.WithContext(ctx.Request.Context()).FindByResetPasswordToken(resetToken)
 	if err != nil {
 		api.Ctx(ctx).BadRequest().WithError(err).Send()
 		return
@@ -533,7 +517,7 @@ func (c *AuthControllerImpl) ResetPassword(ctx *gin.Context) {
 	if user == nil {
 		message := "the user belonging to this code no longer exists. The reset token was " + resetToken
 		api.Ctx(ctx).NotFound().
-			WithCode("user_error").
+			WithCode(api.CodeInvalidToken).
 			WithDescription(message).
 			Send()
 		return
@@ -551,7 +535,7 @@ func (c *AuthControllerImpl) ResetPassword(ctx *gin.Context) {
 	user.SetResetToken("")
 
 	// Update the user
-	if _, err := c.userService.Update(user.ID, user); err != nil {
+	if _, err := c.userService.WithContext(ctx.Request.Context()).Update(user.ID, user); err != nil {
 		api.Ctx(ctx).BadRequest().WithError(err).Send()
 		return
 	}
@@ -569,11 +553,10 @@ func (c *AuthControllerImpl) ResetPassword(ctx *gin.Context) {
 //	@Produce		json
 //	@Param			password	body		models.UserPasswordConfirmation	true	"New password"
 //	@Success		201			{object}	api.Success
-//	@Failure		400			{object}	api.Error
-//	@Failure		401			{object}	api.Error
-//	@Failure		403			{object}	api.Error
-//	@Failure		404			{object}	api.Error
-//	@Failure		412			{object}	api.Error
+//	@Failure		400			{object}	api.Problem
+//	@Failure		401			{object}	api.Problem
+//	@Failure		403			{object}	api.Problem
+//	@Failure		404			{object}	api.Problem
 //	@Router			/auth/change-password [post]
 func (c *AuthControllerImpl) ChangePassword(ctx *gin.Context) {
 
@@ -581,7 +564,7 @@ func (c *AuthControllerImpl) ChangePassword(ctx *gin.Context) {
 	user, err := middlewares.GetUserFromContext(ctx)
 	if err != nil || user == nil {
 		api.Ctx(ctx).Unauthorized().
-			WithCode("user_error").
+			WithCode(api.CodeNotAuthenticated).
 			WithDescription("The user must be logged in").
 			Send()
 		ctx.Abort()
@@ -590,17 +573,7 @@ func (c *AuthControllerImpl) ChangePassword(ctx *gin.Context) {
 
 	// Get the new password from the body
 	var payload *models.UserPasswordConfirmation
-	if err := ctx.ShouldBindJSON(&payload); err != nil {
-		api.Ctx(ctx).BadRequest().WithError(err).Send()
-		return
-	}
-
-	// Check if the passwords match
-	if payload.Password != payload.PasswordConfirmation {
-		api.Ctx(ctx).PreconditionFailed().
-			WithCode("password_error").
-			WithDescription("passwords do not match").
-			Send()
+	if !api.Bind(ctx, &payload) {
 		return
 	}
 
@@ -613,7 +586,7 @@ func (c *AuthControllerImpl) ChangePassword(ctx *gin.Context) {
 	user.Password = hashedPassword
 
 	// Update the user
-	if _, err := c.userService.Update(user.ID, user); err != nil {
+	if _, err := c.userService.WithContext(ctx.Request.Context()).Update(user.ID, user); err != nil {
 		api.Ctx(ctx).BadRequest().WithError(err).Send()
 		return
 	}
@@ -11,6 +11,9 @@ import (
 type Controllers struct {
 	AuthController AuthController
 	UserController UserController
+
+	OrganizationController OrganizationController
+	InvitationController   InvitationController
 }
 
 var (
@@ -31,6 +34,8 @@ func NewControllers(s *services.Services) *Controllers {
 
 // initialize initializes all controllers.
 func (c *Controllers) initialize(s *services.Services) {
-	c.AuthController = NewAuthController(s.UserService, s.MailService)
+	c.AuthController = NewAuthController(s.UserService, s.MailService, s.RegistrationService)
 	c.UserController = NewUserController(s.UserService)
+	c.OrganizationController = NewOrganizationController(s.OrganizationService, s.MembershipService)
+	c.InvitationController = NewInvitationController(s.InvitationService, s.MailService, s.RegistrationService)
 }
@@ -0,0 +1,461 @@
+package controllers
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/go-api-template/go-backend/models"
+	"github.com/go-api-template/go-backend/modules/i18n"
+	"github.com/go-api-template/go-backend/modules/metrics"
+	"github.com/go-api-template/go-backend/modules/middlewares"
+	api "github.com/go-api-template/go-backend/modules/utils/api"
+	"github.com/go-api-template/go-backend/services"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"strings"
+)
+
+// InvitationController is the controller for the invitations to join an organization
+// It declares the methods that the controller must implement
+type InvitationController interface {
+	List(ctx *gin.Context)
+	Create(ctx *gin.Context)
+	Resend(ctx *gin.Context)
+	Revoke(ctx *gin.Context)
+
+	GetByToken(ctx *gin.Context)
+	Accept(ctx *gin.Context)
+	SignUp(ctx *gin.Context)
+}
+
+// InvitationControllerImpl is the controller for the invitations
+// It implements the InvitationController interface
+type InvitationControllerImpl struct {
+	invitationService   services.InvitationService
+	mailerService       services.MailerService
+	registrationService services.RegistrationService
+}
+
+// InvitationControllerImpl implements the InvitationController interface
+var _ InvitationController = &InvitationControllerImpl{}
+
+func NewInvitationController(invitationService services.InvitationService, mailerService services.MailerService, registrationService services.RegistrationService) InvitationController {
+	return &InvitationControllerImpl{invitationService: invitationService, mailerService: mailerService, registrationService: registrationService}
+}
+
+// List godoc
+//
+//	@Summary		List the invitations of an organization
+//	@Description	List the invitations of an organization. The current user must be an admin of the organization.
+//	@Description	Filter with filter[field][operator]=value on email, role, expires_at, accepted_at and created_at.
+//	@Tags			invitation
+//	@Accept			json
+//	@Produce		json
+//	@Param			organization_id	path		string	true	"Organization id"
+//	@Param			page			query		int		false	"Page number"
+//	@Param			limit			query		int		false	"Number of items per page, 20 by default and 100 at most"
+//	@Param			cursor			query		string	false	"Cursor of the page, given empty for the first page, to paginate with cursors instead of page numbers"
+//	@Param			sort			query		string	false	"Comma separated fields to sort by, descending when prefixed with a minus sign"	example(-created_at)
+//	@Success		200				{object}	api.Page[models.Invitation]
+//	@Failure		400				{object}	api.Problem
+//	@Failure		403				{object}	api.Problem
+//	@Failure		500				{object}	api.Problem
+//	@Router			/organizations/{organization_id}/invitations [get]
+func (c *InvitationControllerImpl) List(ctx *gin.Context) {
+	// Get the organization from the context
+	organization, err := middlewares.GetOrganizationFromContext(ctx)
+	if err != nil {
+		api.Ctx(ctx).BadRequest().WithError(err).Send()
+		return
+	}
+
+	// Get the query parameters
+	queryParams, err := api.GetFilter(ctx, models.InvitationFields)
+	if err != nil {
+		api.Ctx(ctx).BadRequest().WithCode(api.CodeInvalidFilter).WithError(err).Send()
+		return
+	}
+
+	// Find the invitations
+	invitations, total, err := c.invitationService.WithContext(ctx.Request.Context()).FindAll(organization.ID, queryParams)
+	if err != nil {
+		api.Ctx(ctx).InternalServerError().WithError(err).Send()
+		return
+	}
+	for i := range invitations {
+		invitations[i] = invitations[i].Response()
+	}
+
+	// Send the response
+	api.Ctx(ctx).Ok().SendPage(api.NewPage(ctx, invitations, total, queryParams))
+}
+
+// Create godoc
+//
+//	@Summary		Invite someone in an organization
+//	@Description	Send an invitation by email to join an organization. The current user must be an admin of the organization, only owners can invite owners.
+//	@Tags			invitation
+//	@Accept			json
+//	@Produce		json
+//	@Param			organization_id	path		string					true	"Organization id"
+//	@Param			invitation		body		models.InvitationInput	true	"Invitation"
+//	@Success		201				{object}	models.Invitation
+//	@Failure		400				{object}	api.Problem
+//	@Failure		403				{object}	api.Problem
+//	@Failure		409				{object}	api.Problem
+//	@Failure		500				{object}	api.Problem
+//	@Router			/organizations/{organization_id}/invitations [post]
+func (c *InvitationControllerImpl) Create(ctx *gin.Context) {
+	// Get the user and the organization from the context
+	user, err := middlewares.GetUserFromContext(ctx)
+	if err != nil {
+		api.Ctx(ctx).BadRequest().WithError(err).Send()
+		return
+	}
+	organization, err := middlewares.GetOrganizationFromContext(ctx)
+	if err != nil {
+		api.Ctx(ctx).BadRequest().WithError(err).Send()
+		return
+	}
+
+	// Bind the request body to the payload
+	var payload *models.InvitationInput
+	if !api.Bind(ctx, &payload) {
+		return
+	}
+	if _, err := models.ParseOrganizationRole(payload.Role.String()); err != nil {
+		api.Ctx(ctx).BadRequest().WithError(err).Send()
+		return
+	}
+
+	// Only owners can invite owners
+	if payload.Role.IsOwner() && !isOrganizationOwner(ctx) {
+		api.Ctx(ctx).Forbidden().
+			WithCode(api.CodeOwnerRoleRequired).
+			WithDescription("Only an owner can invite another owner").
+			Send()
+		return
+	}
+
+	// Create the invitation
+	invitation, err := c.invitationService.WithContext(ctx.Request.Context()).Create(organization.ID, user.ID, payload)
+	if err != nil {
+		if strings.Contains(err.Error(), "already exist") {
+			api.Ctx(ctx).Conflict().WithCode(api.CodeInvitationExists).WithError(err).Send()
+			return
+		}
+		if strings.Contains(err.Error(), "already a member") {
+			api.Ctx(ctx).Conflict().WithCode(api.CodeAlreadyMember).WithError(err).Send()
+			return
+		}
+		api.Ctx(ctx).InternalServerError().WithError(err).Send()
+		return
+	}
+
+	// Send the invitation by email in background
+	c.sendInvitation(ctx, invitation)
+
+	// Send the response
+	api.Ctx(ctx).Created().SendRaw(invitation.Response())
+}
+
+// Resend godoc
+//
+//	@Summary		Resend an invitation
+//	@Description	Generate a new link for a pending invitation, extend its expiry and send it again by email
+//	@Tags			invitation
+//	@Accept			json
+//	@Produce		json
+//	@Param			organization_id	path		string	true	"Organization id"
+//	@Param			invitation_id	path		string	true	"Invitation id"
+//	@Success		200				{object}	models.Invitation
+//	@Failure		400				{object}	api.Problem
+//	@Failure		403				{object}	api.Problem
+//	@Failure		404				{object}	api.Problem
+//	@Failure		409				{object}	api.Problem
+//	@Router			/organizations/{organization_id}/invitations/{invitation_id}/resend [post]
+func (c *InvitationControllerImpl) Resend(ctx *gin.Context) {
+	// Get the organization from the context
+	organization, err := middlewares.GetOrganizationFromContext(ctx)
+	if err != nil {
+		api.Ctx(ctx).BadRequest().WithError(err).Send()
+		return
+	}
+
+	// Parse the invitation id to uuid
+	id, err := uuid.Parse(ctx.Param("invitation_id"))
+	if err != nil {
+		api.Ctx(ctx).BadRequest().WithError(err).Send()
+		return
+	}
+
+	// Renew the invitation
+	invitation, err := c.invitationService.WithContext(ctx.Request.Context()).Resend(organization.ID, id)
+	if err != nil {
+		if strings.Contains(err.Error(), "already accepted") {
+			api.Ctx(ctx).Conflict().WithCode(api.CodeInvitationAccepted).WithError(err).Send()
+			return
+		}
+		api.Ctx(ctx).InternalServerError().WithError(err).Send()
+		return
+	}
+	if invitation == nil {
+		api.Ctx(ctx).NotFound().
+			WithCode(api.CodeInvitationNotFound).
+			WithDescription("invitation not found").
+			Send()
+		return
+	}
+
+	// Send the invitation by email in background
+	c.sendInvitation(ctx, invitation)
+
+	// Send the response
+	api.Ctx(ctx).Ok().SendRaw(invitation.Response())
+}
+
+// Revoke godoc
+//
+//	@Summary		Revoke an invitation
+//	@Description	Revoke a pending invitation, its link can no longer be used
+//	@Tags			invitation
+//	@Accept			json
+//	@Produce		json
+//	@Param			organization_id	path		string	true	"Organization id"
+//	@Param			invitation_id	path		string	true	"Invitation id"
+//	@Success		204				{object}	api.Success
+//	@Failure		400				{object}	api.Problem
+//	@Failure		403				{object}	api.Problem
+//	@Failure		404				{object}	api.Problem
+//	@Router			/organizations/{organization_id}/invitations/{invitation_id} [delete]
+func (c *InvitationControllerImpl) Revoke(ctx *gin.Context) {
+	// Get the organization from the context
+	organization, err := middlewares.GetOrganizationFromContext(ctx)
+	if err != nil {
+		api.Ctx(ctx).BadRequest().WithError(err).Send()
+		return
+	}
+
+	// Parse the invitation id to uuid
+	id, err := uuid.Parse(ctx.Param("invitation_id"))
+	if err != nil {
+		api.Ctx(ctx).BadRequest().WithError(err).Send()
+		return
+	}
+
+	// Revoke the invitation
+	if err := c.invitationService.WithContext(ctx.Request.Context()).Revoke(organization.ID, id); err != nil {
+		if strings.Contains(err.Error(), "unknown invitation") {
+			api.Ctx(ctx).NotFound().WithCode(api.CodeInvitationNotFound).WithError(err).Send()
+			return
+		}
+		api.Ctx(ctx).InternalServerError().WithError(err).Send()
+		return
+	}
+
+	api.Ctx(ctx).NoContent().WithDescription("invitation revoked").Send()
+}
+
+// GetByToken godoc
+//
+//	@Summary		Get an invitation
+//	@Description	Get an invitation from the token sent by email
+//	@Tags			invitation
+//	@Accept			json
+//	@Produce		json
+//	@Param			token	path		string	true	"Invitation token sent by email"
+//	@Success		200		{object}	models.Invitation
+//	@Failure		400		{object}	api.Problem
+//	@Failure		404		{object}	api.Problem
+//	@Failure		410		{object}	api.Problem
+//	@Router			/invitations/{token} [get]
+func (c *InvitationControllerImpl) GetByToken(ctx *gin.Context) {
+	// Get the pending invitation
+	invitation, ok := c.pendingInvitation(ctx)
+	if !ok {
+		return
+	}
+
+	// Send the response
+	api.Ctx(ctx).Ok().SendRaw(invitation.Response())
+}
+
+// Accept godoc
+//
+//	@Summary		Accept an invitation
+//	@Description	Accept an invitation with the account of the current user. The email address of the account must be the invited one.
+//	@Tags			invitation
+//	@Accept			json
+//	@Produce		json
+//	@Param			token	path		string	true	"Invitation token sent by email"
+//	@Success		201		{object}	models.Membership
+//	@Failure		400		{object}	api.Problem
+//	@Failure		403		{object}	api.Problem
+//	@Failure		404		{object}	api.Problem
+//	@Failure		409		{object}	api.Problem
+//	@Failure		410		{object}	api.Problem
+//	@Router			/invitations/{token}/accept [post]
+func (c *InvitationControllerImpl) Accept(ctx *gin.Context) {
+	// Get the user from the context
+	user, err := middlewares.GetUserFromContext(ctx)
+	if err != nil {
+		api.Ctx(ctx).BadRequest().WithError(err).Send()
+		return
+	}
+
+	// Get the pending invitation
+	invitation, ok := c.pendingInvitation(ctx)
+	if !ok {
+		return
+	}
+
+	// The invitation must be accepted by the invited email address
+	if !strings.EqualFold(invitation.Email, user.Email) {
+		api.Ctx(ctx).Forbidden().
+			WithCode(api.CodeInvitationEmailMismatch).
+			WithDescription("This invitation was sent to another email address").
+			Send()
+		return
+	}
+
+	// Add the user to the organization
+	c.accept(ctx, invitation, user)
+}
+
+// SignUp godoc
+//
+//	@Summary		Create an account from an invitation
+//	@Description	Create an account for the invited email address and accept the invitation. The email address is considered as verified.
+//	@Tags			invitation
+//	@Accept			json
+//	@Produce		json
+//	@Param			token		path		string							true	"Invitation token sent by email"
+//	@Param			password	body		models.UserPasswordConfirmation	true	"Password with confirmation"
+//	@Success		201			{object}	models.Membership
+//	@Failure		400			{object}	api.Problem
+//	@Failure		403			{object}	api.Problem
+//	@Failure		404			{object}	api.Problem
+//	@Failure		409			{object}	api.Problem
+//	@Failure		410			{object}	api.Problem
+//	@Router			/invitations/{token}/signup [post]
+func (c *InvitationControllerImpl) SignUp(ctx *gin.Context) {
+	// Get the password from the body
+	var payload *models.UserPasswordConfirmation
+	if !api.Bind(ctx, &payload) {
+		return
+	}
+
+	// Get the pending invitation
+	invitation, ok := c.pendingInvitation(ctx)
+	if !ok {
+		return
+	}
+
+	// Check the registration policy
+	if err := c.registrationService.CanSignUpWithInvitation(invitation.Email); err != nil {
+		api.Ctx(ctx).Forbidden().
+			WithCode(api.CodeRegistrationDenied).
+			WithError(err).
+			Send()
+		return
+	}
+
+	// Sign up the user and add it to the organization, the email address is proven by the invitation
+	_, membership, err := c.invitationService.WithContext(ctx.Request.Context()).SignUp(invitation, &models.UserSignUp{
+		Email:                invitation.Email,
+		Password:             payload.Password,
+		PasswordConfirmation: payload.PasswordConfirmation,
+		Locale:               i18n.Locale(ctx),
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "email already exist") {
+			api.Ctx(ctx).Conflict().
+				WithCode(api.CodeAccountExists).
+				WithDescription("An account already exists for this email address, sign in to accept the invitation").
+				Send()
+			return
+		}
+		c.acceptFailed(ctx, err)
+		return
+	}
+	metrics.SignUps.WithLabelValues("invitation").Inc()
+
+	// Send the response
+	membership.Organization = invitation.Organization
+	api.Ctx(ctx).Created().SendRaw(membership)
+}
+
+// pendingInvitation gets the invitation from the token in the url
+// It sends an error response and returns false if the invitation cannot be accepted
+func (c *InvitationControllerImpl) pendingInvitation(ctx *gin.Context) (*models.Invitation, bool) {
+	// Get the invitation token passed in the url
+	token := ctx.Params.ByName("token")
+
+	// Get the invitation with the token
+	invitation, err := c.invitationService.WithContext(ctx.Request.Context()).FindByToken(token)
+	if err != nil {
+		api.Ctx(ctx).BadRequest().WithError(err).Send()
+		return nil, false
+	}
+	if invitation == nil {
+		api.Ctx(ctx).NotFound().
+			WithCode(api.CodeInvitationNotFound).
+			WithDescription("invitation not found").
+			Send()
+		return nil, false
+	}
+	if invitation.IsAccepted() {
+		api.Ctx(ctx).Gone().
+			WithCode(api.CodeInvitationAccepted).
+			WithDescription("invitation already accepted").
+			Send()
+		return nil, false
+	}
+	if invitation.IsExpired() {
+		api.Ctx(ctx).Gone().
+			WithCode(api.CodeInvitationExpired).
+			WithDescription("invitation expired").
+			Send()
+		return nil, false
+	}
+
+	return invitation, true
+}
+
+// accept adds the user to the organization of the invitation and sends the membership
+func (c *InvitationControllerImpl) accept(ctx *gin.Context, invitation *models.Invitation, user *models.User) {
+	membership, err := c.invitationService.WithContext(ctx.Request.Context()).Accept(invitation, user.ID)
+	if err != nil {
+		c.acceptFailed(ctx, err)
+		return
+	}
+
+	// Send the response
+	membership.Organization = invitation.Organization
+	api.Ctx(ctx).Created().SendRaw(membership)
+}
+
+// acceptFailed sends the error response of an invitation that could not be accepted
+func (c *InvitationControllerImpl) acceptFailed(ctx *gin.Context, err error) {
+	if errors.Is(err, services.ErrInvitationAccepted) {
+		api.Ctx(ctx).Gone().
+			WithCode(api.CodeInvitationAccepted).
+			WithDescription("invitation already accepted").
+			Send()
+		return
+	}
+	if strings.Contains(err.Error(), "already a member") {
+		api.Ctx(ctx).Conflict().WithCode(api.CodeAlreadyMember).WithError(err).Send()
+		return
+	}
+	api.Ctx(ctx).InternalServerError().WithError(err).Send()
+}
+
+// sendInvitation sends the invitation by email in background
+func (c *InvitationControllerImpl) sendInvitation(ctx *gin.Context, invitation *models.Invitation) {
+	if c.mailerService == nil {
+		return
+	}
+	if err := c.mailerService.SendInvitation(ctx.Request.Context(), invitation); err != nil {
+		log.Ctx(ctx.Request.Context()).Error().Err(err).Msg("Failed to send the invitation")
+	}
+}
@@ -0,0 +1,451 @@
+package controllers
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/go-api-template/go-backend/models"
+	"github.com/go-api-template/go-backend/modules/middlewares"
+	api "github.com/go-api-template/go-backend/modules/utils/api"
+	"github.com/go-api-template/go-backend/services"
+	"github.com/google/uuid"
+	"strings"
+)
+
+// OrganizationController is the controller for organizations
+// It declares the methods that the controller must implement
+type OrganizationController interface {
+	List(ctx *gin.Context)
+	Create(ctx *gin.Context)
+	GetById(ctx *gin.Context)
+	Update(ctx *gin.Context)
+	Delete(ctx *gin.Context)
+
+	ListMembers(ctx *gin.Context)
+	UpdateMember(ctx *gin.Context)
+	DeleteMember(ctx *gin.Context)
+}
+
+// OrganizationControllerImpl is the controller for organizations
+// It implements the OrganizationController interface
+type OrganizationControllerImpl struct {
+	organizationService services.OrganizationService
+	membershipService   services.MembershipService
+}
+
+// OrganizationControllerImpl implements the OrganizationController interface
+var _ OrganizationController = &OrganizationControllerImpl{}
+
+func NewOrganizationController(organizationService services.OrganizationService, membershipService services.MembershipService) OrganizationController {
+	return &OrganizationControllerImpl{organizationService: organizationService, membershipService: membershipService}
+}
+
+// List godoc
+//
+//	@Summary		List the organizations
+//	@Description	List the organizations of the current user. Platform admins get all the organizations.
+//	@Description	Filter with filter[field][operator]=value on name, slug, created_at and updated_at.
+//	@Tags			organization
+//	@Accept			json
+//	@Produce		json
+//	@Param			page	query		int		false	"Page number"
+//	@Param			limit	query		int		false	"Number of items per page, 20 by default and 100 at most"
+//	@Param			cursor	query		string	false	"Cursor of the page, given empty for the first page, to paginate with cursors instead of page numbers"
+//	@Param			sort	query		string	false	"Comma separated fields to sort by, descending when prefixed with a minus sign"	example(name)
+//	@Success		200		{object}	api.Page[models.Organization]
+//	@Failure		400		{object}	api.Problem
+//	@Failure		500		{object}	api.Problem
+//	@Router			/organizations [get]
+func (c *OrganizationControllerImpl) List(ctx *gin.Context) {
+	// Get the user from the context
+	user, err := middlewares.GetUserFromContext(ctx)
+	if err != nil {
+		api.Ctx(ctx).BadRequest().WithError(err).Send()
+		return
+	}
+
+	// Get the query parameters
+	queryParams, err := api.GetFilter(ctx, models.OrganizationFields)
+	if err != nil {
+		api.Ctx(ctx).BadRequest().WithCode(api.CodeInvalidFilter).WithError(err).Send()
+		return
+	}
+
+	// Find the organizations
+	var organizations []models.Organization
+	var total int64
+	if user.Role.IsPlatformAdmin() {
+		organizations, total, err = c.organizationService.WithContext(ctx.Request.Context()).FindAll(queryParams)
+	} else {
+		organizations, total, err = c.organizationService.WithContext(ctx.Request.Context()).FindAllByUser(user.ID, queryParams)
+	}
+	if err != nil {
+		api.Ctx(ctx).InternalServerError().WithError(err).Send()
+		return
+	}
+
+	// Send the response
+	api.Ctx(ctx).Ok().SendPage(api.NewPage(ctx, organizations, total, queryParams))
+}
+
+// Create godoc
+//
+//	@Summary		Create an organization
+//	@Description	Create an organization. The current user becomes its owner.
+//	@Tags			organization
+//	@Accept			json
+//	@Produce		json
+//	@Param			organization	body		models.OrganizationInput	true	"Organization information"
+//	@Success		201				{object}	models.Organization
+//	@Failure		400				{object}	api.Problem
+//	@Failure		409				{object}	api.Problem
+//	@Failure		500				{object}	api.Problem
+//	@Router			/organizations [post]
+func (c *OrganizationControllerImpl) Create(ctx *gin.Context) {
+	// Get the user from the context
+	user, err := middlewares.GetUserFromContext(ctx)
+	if err != nil {
+		api.Ctx(ctx).BadRequest().WithError(err).Send()
+		return
+	}
+
+	// Bind the request body to the payload
+	var payload *models.OrganizationInput
+	if !api.Bind(ctx, &payload) {
+		return
+	}
+
+	// Create the organization
+	organization, err := c.organizationService.WithContext(ctx.Request.Context()).Create(user, payload)
+	if err != nil {
+		if strings.Contains(err.Error(), "already exist") {
+			api.Ctx(ctx).Conflict().WithCode(api.CodeOrganizationSlugTaken).WithError(err).Send()
+			return
+		}
+		api.Ctx(ctx).InternalServerError().WithError(err).Send()
+		return
+	}
+
+	// Send the response
+	api.Ctx(ctx).Created().SendRaw(organization)
+}
+
+// GetById godoc
+//
+//	@Summary		Get an organization
+//	@Description	Get an organization the current user is a member of
+//	@Tags			organization
+//	@Accept			json
+//	@Produce		json
+//	@Param			organization_id	path		string	true	"Organization id"
+//	@Success		200				{object}	models.Organization
+//	@Failure		400				{object}	api.Problem
+//	@Failure		403				{object}	api.Problem
+//	@Failure		404				{object}	api.Problem
+//	@Router			/organizations/{organization_id} [get]
+func (c *OrganizationControllerImpl) GetById(ctx *gin.Context) {
+	// Get the organization from the context
+	organization, err := middlewares.GetOrganizationFromContext(ctx)
+	if err != nil {
+		api.Ctx(ctx).BadRequest().WithError(err).Send()
+		return
+	}
+
+	// Send the response, it can be cached until the organization is modified
+	ctx.Header("Cache-Control", "private, max-age=60")
+	api.Ctx(ctx).Ok().SendRaw(organization)
+}
+
+// Update godoc
+//
+//	@Summary		Update an organization
+//	@Description	Update an organization. The current user must be an admin of the organization.
+//	@Tags			organization
+//	@Accept			json
+//	@Produce		json
+//	@Param			organization_id	path		string						true	"Organization id"
+//	@Param			organization	body		models.OrganizationInput	true	"Organization information"
+//	@Success		200				{object}	models.Organization
+//	@Failure		400				{object}	api.Problem
+//	@Failure		403				{object}	api.Problem
+//	@Failure		404				{object}	api.Problem
+//	@Failure		409				{object}	api.Problem
+//	@Failure		500				{object}	api.Problem
+//	@Router			/organizations/{organization_id} [patch]
+func (c *OrganizationControllerImpl) Update(ctx *gin.Context) {
+	// Get the organization from the context
+	organization, err := middlewares.GetOrganizationFromContext(ctx)
+	if err != nil {
+		api.Ctx(ctx).BadRequest().WithError(err).Send()
+		return
+	}
+
+	// Retrieve the organization from the request body
+	var payload models.OrganizationInput
+	if !api.Bind(ctx, &payload) {
+		return
+	}
+
+	// Fields to update
+	organization.Name = strings.TrimSpace(payload.Name)
+	if payload.Slug != "" {
+		organization.Slug = payload.Slug
+	}
+
+	organization, err = c.organizationService.WithContext(ctx.Request.Context()).Update(organization.ID, organization)
+	if err != nil {
+		if strings.Contains(err.Error(), "already exist") {
+			api.Ctx(ctx).Conflict().WithCode(api.CodeOrganizationSlugTaken).WithError(err).Send()
+			return
+		}
+		api.Ctx(ctx).InternalServerError().WithError(err).Send()
+		return
+	}
+	if organization == nil {
+		api.Ctx(ctx).NotFound().
+			WithCode(api.CodeOrganizationNotFound).
+			WithDescription("organization not found").
+			Send()
+		return
+	}
+
+	api.Ctx(ctx).Ok().SendRaw(organization)
+}
+
+// Delete godoc
+//
+//	@Summary		Delete an organization
+//	@Description	Delete an organization and all its memberships. The current user must be an owner of the organization.
+//	@Tags			organization
+//	@Accept			json
+//	@Produce		json
+//	@Param			organization_id	path		string	true	"Organization id"
+//	@Success		204				{object}	api.Success
+//	@Failure		400				{object}	api.Problem
+//	@Failure		403				{object}	api.Problem
+//	@Failure		404				{object}	api.Problem
+//	@Failure		500				{object}	api.Problem
+//	@Router			/organizations/{organization_id} [delete]
+func (c *OrganizationControllerImpl) Delete(ctx *gin.Context) {
+	// Get the organization from the context
+	organization, err := middlewares.GetOrganizationFromContext(ctx)
+	if err != nil {
+		api.Ctx(ctx).BadRequest().WithError(err).Send()
+		return
+	}
+
+	// Delete the organization from the database
+	err = c.organizationService.WithContext(ctx.Request.Context()).Delete(organization.ID)
+	if err != nil {
+		api.Ctx(ctx).InternalServerError().WithError(err).Send()
+		return
+	}
+
+	api.Ctx(ctx).NoContent().WithDescription("organization deleted").Send()
+}
+
+// ListMembers godoc
+//
+//	@Summary		List the members of an organization
+//	@Description	List the members of an organization the current user is a member of.
+//	@Description	Filter with filter[field][operator]=value on user_id, role and created_at.
+//	@Tags			organization
+//	@Accept			json
+//	@Produce		json
+//	@Param			organization_id	path		string	true	"Organization id"
+//	@Param			page			query		int		false	"Page number"
+//	@Param			limit			query		int		false	"Number of items per page, 20 by default and 100 at most"
+//	@Param			cursor			query		string	false	"Cursor of the page, given empty for the first page, to paginate with cursors instead of page numbers"
+//	@Param			sort			query		string	false	"Comma separated fields to sort by, descending when prefixed with a minus sign"	example(-created_at)
+//	@Success		200				{object}	api.Page[models.Membership]
+//	@Failure		400				{object}	api.Problem
+//	@Failure		403				{object}	api.Problem
+//	@Failure		404				{object}	api.Problem
+//	@Failure		500				{object}	api.Problem
+//	@Router			/organizations/{organization_id}/members [get]
+func (c *OrganizationControllerImpl) ListMembers(ctx *gin.Context) {
+	// Get the organization from the context
+	organization, err := middlewares.GetOrganizationFromContext(ctx)
+	if err != nil {
+		api.Ctx(ctx).BadRequest().WithError(err).Send()
+		return
+	}
+
+	// Get the query parameters
+	queryParams, err := api.GetFilter(ctx, models.MembershipFields)
+	if err != nil {
+		api.Ctx(ctx).BadRequest().WithCode(api.CodeInvalidFilter).WithError(err).Send()
+		return
+	}
+
+	// Find the members
+	memberships, total, err := c.membershipService.WithContext(ctx.Request.Context()).FindAll(organization.ID, queryParams)
+	if err != nil {
+		api.Ctx(ctx).InternalServerError().WithError(err).Send()
+		return
+	}
+
+	// Only send the public information of the users
+	for i := range memberships {
+		if memberships[i].User != nil {
+			user := memberships[i].User.Response()
+			memberships[i].User = &user
+		}
+	}
+
+	// Send the response
+	api.Ctx(ctx).Ok().SendPage(api.NewPage(ctx, memberships, total, queryParams))
+}
+
+// UpdateMember godoc
+//
+//	@Summary		Change the role of a member
+//	@Description	Change the role of a member. The current user must be an admin of the organization, only owners can grant or remove the owner role.
+//	@Tags			organization
+//	@Accept			json
+//	@Produce		json
+//	@Param			organization_id	path		string					true	"Organization id"
+//	@Param			user_id			path		string					true	"User id"
+//	@Param			role			body		models.MembershipRole	true	"Role of the member"
+//	@Success		200				{object}	models.Membership
+//	@Failure		400				{object}	api.Problem
+//	@Failure		403				{object}	api.Problem
+//	@Failure		404				{object}	api.Problem
+//	@Failure		412				{object}	api.Problem
+//	@Router			/organizations/{organization_id}/members/{user_id} [patch]
+func (c *OrganizationControllerImpl) UpdateMember(ctx *gin.Context) {
+	// Get the organization from the context
+	organization, err := middlewares.GetOrganizationFromContext(ctx)
+	if err != nil {
+		api.Ctx(ctx).BadRequest().WithError(err).Send()
+		return
+	}
+
+	// Parse the user id to uuid
+	uid, err := uuid.Parse(ctx.Param("user_id"))
+	if err != nil {
+		api.Ctx(ctx).BadRequest().WithError(err).Send()
+		return
+	}
+
+	// Retrieve the role from the request body
+	var payload models.MembershipRole
+	if !api.Bind(ctx, &payload) {
+		return
+	}
+	if _, err := models.ParseOrganizationRole(payload.Role.String()); err != nil {
+		api.Ctx(ctx).BadRequest().WithError(err).Send()
+		return
+	}
+
+	// Get the member from the database
+	membership, err := c.membershipService.WithContext(ctx.Request.Context()).FindByUser(organization.ID, uid)
+	if err != nil {
+		api.Ctx(ctx).InternalServerError().WithError(err).Send()
+		return
+	}
+	if membership == nil {
+		api.Ctx(ctx).NotFound().
+			WithCode(api.CodeMemberNotFound).
+			WithDescription("member not found").
+			Send()
+		return
+	}
+
+	// Only owners can grant or remove the owner role
+	if (payload.Role.IsOwner() || membership.Role.IsOwner()) && !isOrganizationOwner(ctx) {
+		api.Ctx(ctx).Forbidden().
+			WithCode(api.CodeOwnerRoleRequired).
+			WithDescription("Only an owner can grant or remove the owner role").
+			Send()
+		return
+	}
+
+	// Update the role
+	membership, err = c.membershipService.WithContext(ctx.Request.Context()).UpdateRole(organization.ID, uid, payload.Role)
+	if err != nil {
+		if errors.Is(err, services.ErrLastOwner) {
+			api.Ctx(ctx).PreconditionFailed().WithCode(api.CodeLastOwner).WithError(err).Send()
+			return
+		}
+		api.Ctx(ctx).InternalServerError().WithError(err).Send()
+		return
+	}
+
+	api.Ctx(ctx).Ok().SendRaw(membership)
+}
+
+// DeleteMember godoc
+//
+//	@Summary		Remove a member from an organization
+//	@Description	Remove a member from an organization. The current user must be an admin of the organization, only owners can remove another owner.
+//	@Tags			organization
+//	@Accept			json
+//	@Produce		json
+//	@Param			organization_id	path		string	true	"Organization id"
+//	@Param			user_id			path		string	true	"User id"
+//	@Success		204				{object}	api.Success
+//	@Failure		400				{object}	api.Problem
+//	@Failure		403				{object}	api.Problem
+//	@Failure		404				{object}	api.Problem
+//	@Failure		412				{object}	api.Problem
+//	@Router			/organizations/{organization_id}/members/{user_id} [delete]
+func (c *OrganizationControllerImpl) DeleteMember(ctx *gin.Context) {
+	// Get the organization from the context
+	organization, err := middlewares.GetOrganizationFromContext(ctx)
+	if err != nil {
+		api.Ctx(ctx).BadRequest().WithError(err).Send()
+		return
+	}
+
+	// Parse the user id to uuid
+	uid, err := uuid.Parse(ctx.Param("user_id"))
+	if err != nil {
+		api.Ctx(ctx).BadRequest().WithError(err).Send()
+		return
+	}
+
+	// Get the member from the database
+	membership, err := c.membershipService.WithContext(ctx.Request.Context()).FindByUser(organization.ID, uid)
+	if err != nil {
+		api.Ctx(ctx).InternalServerError().WithError(err).Send()
+		return
+	}
+	if membership == nil {
+		api.Ctx(ctx).NotFound().
+			WithCode(api.CodeMemberNotFound).
+			WithDescription("member not found").
+			Send()
+		return
+	}
+
+	// Only owners can remove another owner
+	if membership.Role.IsOwner() && !isOrganizationOwner(ctx) {
+		api.Ctx(ctx).Forbidden().
+			WithCode(api.CodeOwnerRoleRequired).
+			WithDescription("Only an owner can remove another owner").
+			Send()
+		return
+	}
+
+	// Remove the member
+	if err := c.membershipService.WithContext(ctx.Request.Context()).Delete(organization.ID, uid); err != nil {
+		if errors.Is(err, services.ErrLastOwner) {
+			api.Ctx(ctx).PreconditionFailed().WithCode(api.CodeLastOwner).WithError(err).Send()
+			return
+		}
+		api.Ctx(ctx).InternalServerError().WithError(err).Send()
+		return
+	}
+
+	api.Ctx(ctx).NoContent().WithDescription("member removed").Send()
+}
+
+// isOrganizationOwner checks if the current user is an owner of the organization in the context
+// Platform admins are considered as owners of every organization
+func isOrganizationOwner(ctx *gin.Context) bool {
+	if user, err := middlewares.GetUserFromContext(ctx); err == nil && user.Role.IsPlatformAdmin() {
+		return true
+	}
+	membership, err := middlewares.GetMembershipFromContext(ctx)
+	return err == nil && membership.Role.IsOwner()
+}
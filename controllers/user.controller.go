@@ -1,6 +1,7 @@
 package controllers
 
 import (
+	"errors"
 	"github.com/gin-gonic/gin"
 	"github.com/go-api-template/go-backend/models"
 	"github.com/go-api-template/go-backend/modules/middlewares"
@@ -42,8 +43,10 @@ func NewUserController(userService services.UserService) UserController {
 //	@Tags			user
 //	@Accept			json
 //	@Produce		json
-//	@Success		200	{object}	models.User
-//	@Failure		400	{object}	api.Error
+//	@Param			If-None-Match	header		string	false	"Entity tag of the version of the user already known by the client"
+//	@Success		200				{object}	models.User
+//	@Success		304				"The user has not been modified"
+//	@Failure		400				{object}	api.Problem
 //	@Router			/users/me [get]
 func (c *UserControllerImpl) GetMe(ctx *gin.Context) {
 	// Get the user from the context
@@ -53,6 +56,11 @@ func (c *UserControllerImpl) GetMe(ctx *gin.Context) {
 		return
 	}
 
+	// The client may already have this version of the user
+	if api.NotModified(ctx, user.ETag()) {
+		return
+	}
+
 	// Send the response
 	api.Ctx(ctx).Ok().SendRaw(user.Response())
 }
@@ -60,15 +68,21 @@ func (c *UserControllerImpl) GetMe(ctx *gin.Context) {
 // UpdateMe godoc
 //
 //	@Summary		Update information about the connected user
-//	@Description	Update information about the connected user
+//	@Description	Update information about the connected user with a JSON Merge Patch or a JSON Patch
+//	@Description	A field is cleared when it is set to null or removed, the role and the verification cannot be updated
 //	@Tags			user
-//	@Accept			json
+//	@Accept			application/merge-patch+json,application/json-patch+json,json
 //	@Produce		json
-//	@Param			user	body		models.User	true	"User information"
-//	@Success		200		{object}	models.User
-//	@Failure		204		{object}	api.Success
-//	@Failure		400		{object}	api.Error
-//	@Failure		500		{object}	api.Error
+//	@Param			If-Match	header		string				true	"Entity tag of the version of the user to update"
+//	@Param			user		body		models.UserUpdate	true	"Fields to update"
+//	@Success		200			{object}	models.User
+//	@Failure		204			{object}	api.Success
+//	@Failure		400			{object}	api.Problem
+//	@Failure		409			{object}	api.Problem
+//	@Failure		412			{object}	api.Problem
+//	@Failure		415			{object}	api.Problem
+//	@Failure		428			{object}	api.Problem
+//	@Failure		500			{object}	api.Problem
 //	@Router			/user/me [patch]
 func (c *UserControllerImpl) UpdateMe(ctx *gin.Context) {
 	// Get the user from the context
@@ -78,26 +92,25 @@ func (c *UserControllerImpl) UpdateMe(ctx *gin.Context) {
 		return
 	}
 
-	// Retrieve the user from the request body
-	var payload models.User
-	if err := ctx.ShouldBindJSON(&payload); err != nil {
-		api.Ctx(ctx).BadRequest().WithError(err).Send()
+	// The client must update the current version of the user
+	if !api.IfMatch(ctx, user.ETag()) {
 		return
 	}
+	updatedAt := user.UpdatedAt
 
-	// Fields to update
-	if payload.Name != "" {
-		user.Name = payload.Name
-	}
-	if payload.FirstName != "" {
-		user.FirstName = payload.FirstName
-	}
-	if payload.LastName != "" {
-		user.LastName = payload.LastName
+	// Apply the patch of the request body, a user can only update its profile
+	payload := models.NewUserUpdate(user)
+	if !api.Patch(ctx, &payload, models.UserUpdateFields[models.RoleUser]) {
+		return
 	}
+	payload.Apply(user)
 
-	user, err = c.userService.Update(user.ID, user)
+	user, err = c.userService.WithContext(ctx.Request.Context()).UpdateIfUnmodified(user.ID, user, updatedAt)
 	if err != nil {
+		if errors.Is(err, services.ErrUserModified) {
+			api.Ctx(ctx).PreconditionFailed().WithCode(api.CodeResourceModified).WithError(err).Send()
+			return
+		}
 		api.Ctx(ctx).InternalServerError().WithError(err).Send()
 		return
 	}
@@ -106,7 +119,7 @@ func (c *UserControllerImpl) UpdateMe(ctx *gin.Context) {
 		return
 	}
 
-	api.Ctx(ctx).Ok().SendRaw(user)
+	api.Ctx(ctx).Ok().WithETag(user.ETag()).SendRaw(user.Response())
 }
 
 // DeleteMe godoc
@@ -116,9 +129,12 @@ func (c *UserControllerImpl) UpdateMe(ctx *gin.Context) {
 //	@Tags			user
 //	@Accept			json
 //	@Produce		json
-//	@Success		204	{object}	api.Success
-//	@Failure		400	{object}	api.Error
-//	@Failure		500	{object}	api.Error
+//	@Param			If-Match	header		string	true	"Entity tag of the version of the user to delete"
+//	@Success		204			{object}	api.Success
+//	@Failure		400			{object}	api.Problem
+//	@Failure		412			{object}	api.Problem
+//	@Failure		428			{object}	api.Problem
+//	@Failure		500			{object}	api.Problem
 //	@Router			/user/me [delete]
 func (c *UserControllerImpl) DeleteMe(ctx *gin.Context) {
 	// Get the user from the context
@@ -128,9 +144,25 @@ func (c *UserControllerImpl) DeleteMe(ctx *gin.Context) {
 		return
 	}
 
-	// Delete the user from the database
-	err = c.userService.Delete(cu.ID)
+	// The client must delete the current version of the user
+	if !api.IfMatch(ctx, cu.ETag()) {
+		return
+	}
+
+	// Delete the user from the database, unless it has been modified in the meantime
+	err = c.userService.WithContext(ctx.Request.Context()).DeleteIfUnmodified(cu.ID, cu.UpdatedAt)
 	if err != nil {
+		if errors.Is(err, services.ErrUserModified) {
+			api.Ctx(ctx).PreconditionFailed().WithCode(api.CodeResourceModified).WithError(err).Send()
+			return
+		}
+		if errors.Is(err, services.ErrLastOwner) {
+			api.Ctx(ctx).PreconditionFailed().
+				WithCode(api.CodeLastOwner).
+				WithDescription("The user is the last owner of an organization, transfer the ownership or delete the organization first").
+				Send()
+			return
+		}
 		api.Ctx(ctx).InternalServerError().WithError(err).Send()
 		return
 	}
@@ -141,32 +173,47 @@ func (c *UserControllerImpl) DeleteMe(ctx *gin.Context) {
 // FindAll godoc
 //
 //	@Summary		Find all users
-//	@Description	Find all users
+//	@Description	Find all users. Filter with filter[field][operator]=value on email, name, first_name, last_name, role, verified, created_at and updated_at.
 //	@Tags			user
 //	@Accept			json
 //	@Produce		json
 //	@Param			page	query		int		false	"Page number"
-//	@Param			limit	query		int		false	"Number of items per page"
-//	@Param			sort	query		string	false	"Sort by field"
-//	@Param			order	query		string	false	"Sort order (asc or desc)"
-//	@Param			search	query		string	false	"Search string"
-//	@Success		200		{object}	[]models.User
-//	@Failure		400		{object}	api.Error
-//	@Failure		500		{object}	api.Error
+//	@Param			limit	query		int		false	"Number of items per page, 20 by default and 100 at most"
+//	@Param			cursor	query		string	false	"Cursor of the page, given empty for the first page, to paginate with cursors instead of page numbers"
+//	@Param			sort	query		string	false	"Comma separated fields to sort by, descending when prefixed with a minus sign"	example(-created_at)
+//	@Param			fields	query		string	false	"Comma separated fields to send, all by default"								example(id,email,name)
+//	@Param			include	query		string	false	"Comma separated relationships to send"											example(memberships)
+//	@Success		200		{object}	api.Page[models.User]
+//	@Failure		400		{object}	api.Problem
+//	@Failure		500		{object}	api.Problem
 //	@Router			/users [get]
 func (c *UserControllerImpl) List(ctx *gin.Context) {
 	// Get the query parameters
-	queryParams := api.GetFilter(ctx)
+	queryParams, err := api.GetFilter(ctx, models.UserFields)
+	if err != nil {
+		api.Ctx(ctx).BadRequest().WithCode(api.CodeInvalidFilter).WithError(err).Send()
+		return
+	}
+
+	// Get the requested fields and relationships
+	selection, err := api.GetSelection(ctx, &models.User{})
+	if err != nil {
+		api.Ctx(ctx).BadRequest().WithCode(api.CodeInvalidSelection).WithError(err).Send()
+		return
+	}
 
 	// Find the users
-	users, err := c.userService.FindAll(queryParams)
+	users, total, err := c.userService.WithContext(ctx.Request.Context()).FindAll(queryParams, selection)
 	if err != nil {
 		api.Ctx(ctx).InternalServerError().WithError(err).Send()
 		return
 	}
 
-	// Send the response
-	api.Ctx(ctx).Ok().SendRaw(users)
+	// Send the response with only the requested fields
+	page := api.NewPage(ctx, users, total, queryParams)
+	api.Ctx(ctx).Ok().SendPage(api.MapPage(page, func(user models.User) any {
+		return selection.Render(user.Response())
+	}))
 }
 
 // FindById godoc
@@ -176,11 +223,13 @@ func (c *UserControllerImpl) List(ctx *gin.Context) {
 //	@Tags			user
 //	@Accept			json
 //	@Produce		json
-//	@Param			id	path		string	true	"User id"
-//	@Success		200	{object}	models.User
-//	@Failure		400	{object}	api.Error
-//	@Failure		404	{object}	api.Success
-//	@Failure		500	{object}	api.Error
+//	@Param			id		path		string	true	"User id"
+//	@Param			fields	query		string	false	"Comma separated fields to send, all by default"	example(id,email,name)
+//	@Param			include	query		string	false	"Comma separated relationships to send"				example(memberships)
+//	@Success		200		{object}	models.User
+//	@Failure		400		{object}	api.Problem
+//	@Failure		404		{object}	api.Success
+//	@Failure		500	{object}	api.Problem
 //	@Router			/users/{id} [get]
 func (c *UserControllerImpl) GetById(ctx *gin.Context) {
 	// Get the user id
@@ -192,37 +241,61 @@ func (c *UserControllerImpl) GetById(ctx *gin.Context) {
 		return
 	}
 
-	// Find the user
-	user, err := c.userService.FindById(uid)
+	// Get the requested fields and relationships
+	selection, err := api.GetSelection(ctx, &models.User{})
+	if err != nil {
+		api.Ctx(ctx).BadRequest().WithCode(api.CodeInvalidSelection).WithError(err).Send()
+		return
+	}
+
+	// Find the user, its update time is needed to compute its entity tag
+	user, err := c.userService.WithContext(ctx.Request.Context()).FindByIdWithSelection(uid, selection.WithColumns("users.updated_at"))
 	if err != nil {
 		api.Ctx(ctx).InternalServerError().WithError(err).Send()
 		return
 	}
 	if user == nil {
 		api.Ctx(ctx).NotFound().
-			WithCode("user_error").
+			WithCode(api.CodeUserNotFound).
 			WithDescription("user not found").
 			Send()
 		return
 	}
 
-	// Send the response
-	api.Ctx(ctx).Ok().SendRaw(user)
+	// The user can be cached until it is modified, its relationships are not tracked by the cache
+	if len(selection.Includes) == 0 {
+		ctx.Header("Cache-Control", "private, max-age=60")
+	}
+
+	// The client may already have this version of the user with the same fields and relationships
+	rendered := selection.Render(user.Response())
+	if api.NotModified(ctx, selection.ETag(user.ETag(), rendered)) {
+		return
+	}
+
+	// Send the response with only the requested fields
+	api.Ctx(ctx).Ok().SendRaw(rendered)
 }
 
 // Update godoc
 //
 //	@Summary		Update a user
-//	@Description	Update a user
+//	@Description	Update a user with a JSON Merge Patch or a JSON Patch
+//	@Description	A field is cleared when it is set to null or removed, the editable fields depend on the role of the editor
 //	@Tags			user
-//	@Accept			json
+//	@Accept			application/merge-patch+json,application/json-patch+json,json
 //	@Produce		json
-//	@Param			id		path		string		true	"User id"
-//	@Param			user	body		models.User	true	"User information"
-//	@Success		200		{object}	models.User
-//	@Failure		400		{object}	api.Error
-//	@Failure		404		{object}	api.Success
-//	@Failure		500		{object}	api.Error
+//	@Param			id			path		string				true	"User id"
+//	@Param			If-Match	header		string				true	"Entity tag of the version of the user to update"
+//	@Param			user		body		models.UserUpdate	true	"Fields to update"
+//	@Success		200			{object}	models.User
+//	@Failure		400			{object}	api.Problem
+//	@Failure		404			{object}	api.Success
+//	@Failure		409			{object}	api.Problem
+//	@Failure		412			{object}	api.Problem
+//	@Failure		415			{object}	api.Problem
+//	@Failure		428			{object}	api.Problem
+//	@Failure		500			{object}	api.Problem
 //	@Router			/users/{id} [patch]
 func (c *UserControllerImpl) Update(ctx *gin.Context) {
 	// Get the user id
@@ -234,55 +307,58 @@ func (c *UserControllerImpl) Update(ctx *gin.Context) {
 		return
 	}
 
-	// Retrieve the user from the request body
-	var payload models.User
-	if err := ctx.ShouldBindJSON(&payload); err != nil {
+	// Get the editor from the context
+	editor, err := middlewares.GetUserFromContext(ctx)
+	if err != nil {
 		api.Ctx(ctx).BadRequest().WithError(err).Send()
 		return
 	}
 
 	// Get the user from the database
-	user, err := c.userService.FindById(uid)
+	user, err := c.userService.WithContext(ctx.Request.Context()).FindById(uid)
 	if err != nil {
 		api.Ctx(ctx).InternalServerError().WithError(err).Send()
 		return
 	}
 	if user == nil {
 		api.Ctx(ctx).NotFound().
-			WithCode("user_error").
+			WithCode(api.CodeUserNotFound).
 			WithDescription("user not found").
 			Send()
 		return
 	}
 
-	// Fields to update
-	if payload.Name != "" {
-		user.Name = payload.Name
-	}
-	if payload.FirstName != "" {
-		user.FirstName = payload.FirstName
-	}
-	if payload.LastName != "" {
-		user.LastName = payload.LastName
+	// The client must update the current version of the user
+	if !api.IfMatch(ctx, user.ETag()) {
+		return
 	}
-	if payload.Role != "" {
-		user.Role = payload.Role
+	updatedAt := user.UpdatedAt
+
+	// Apply the patch of the request body, the editable fields depend on the role of the editor
+	payload := models.NewUserUpdate(user)
+	if !api.Patch(ctx, &payload, models.UserUpdateFields[editor.Role]) {
+		return
 	}
+	payload.Apply(user)
 
-	user, err = c.userService.Update(uid, user)
+	user, err = c.userService.WithContext(ctx.Request.Context()).UpdateIfUnmodified(uid, user, updatedAt)
 	if err != nil {
+		if errors.Is(err, services.ErrUserModified) {
+			api.Ctx(ctx).PreconditionFailed().WithCode(api.CodeResourceModified).WithError(err).Send()
+			return
+		}
 		api.Ctx(ctx).InternalServerError().WithError(err).Send()
 		return
 	}
 	if user == nil {
 		api.Ctx(ctx).NotFound().
-			WithCode("user_error").
+			WithCode(api.CodeUserNotFound).
 			WithDescription("user not found").
 			Send()
 		return
 	}
 
-	api.Ctx(ctx).Ok().SendRaw(user)
+	api.Ctx(ctx).Ok().WithETag(user.ETag()).SendRaw(user.Response())
 }
 
 // Delete godoc
@@ -292,11 +368,14 @@ func (c *UserControllerImpl) Update(ctx *gin.Context) {
 //	@Tags			user
 //	@Accept			json
 //	@Produce		json
-//	@Param			id	path		string	true	"User id"
-//	@Success		204	{object}	api.Success
-//	@Failure		400	{object}	api.Error
-//	@Failure		404	{object}	api.Success
-//	@Failure		500	{object}	api.Error
+//	@Param			id			path		string	true	"User id"
+//	@Param			If-Match	header		string	true	"Entity tag of the version of the user to delete"
+//	@Success		204			{object}	api.Success
+//	@Failure		400			{object}	api.Problem
+//	@Failure		404			{object}	api.Problem
+//	@Failure		412			{object}	api.Problem
+//	@Failure		428			{object}	api.Problem
+//	@Failure		500			{object}	api.Problem
 //	@Router			/users/{id} [delete]
 func (c *UserControllerImpl) Delete(ctx *gin.Context) {
 	// Get the user id
@@ -308,9 +387,39 @@ func (c *UserControllerImpl) Delete(ctx *gin.Context) {
 		return
 	}
 
-	// Delete the user from the database
-	err = c.userService.Delete(uid)
+	// Get the user from the database
+	user, err := c.userService.WithContext(ctx.Request.Context()).FindById(uid)
+	if err != nil {
+		api.Ctx(ctx).InternalServerError().WithError(err).Send()
+		return
+	}
+	if user == nil {
+		api.Ctx(ctx).NotFound().
+			WithCode(api.CodeUserNotFound).
+			WithDescription("user not found").
+			Send()
+		return
+	}
+
+	// The client must delete the current version of the user
+	if !api.IfMatch(ctx, user.ETag()) {
+		return
+	}
+
+	// Delete the user from the database, unless it has been modified in the meantime
+	err = c.userService.WithContext(ctx.Request.Context()).DeleteIfUnmodified(uid, user.UpdatedAt)
 	if err != nil {
+		if errors.Is(err, services.ErrUserModified) {
+			api.Ctx(ctx).PreconditionFailed().WithCode(api.CodeResourceModified).WithError(err).Send()
+			return
+		}
+		if errors.Is(err, services.ErrLastOwner) {
+			api.Ctx(ctx).PreconditionFailed().
+				WithCode(api.CodeLastOwner).
+				WithDescription("The user is the last owner of an organization, transfer the ownership or delete the organization first").
+				Send()
+			return
+		}
 		api.Ctx(ctx).InternalServerError().WithError(err).Send()
 		return
 	}
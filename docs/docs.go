@@ -49,31 +49,25 @@ const docTemplate = `{
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/api.Error"
+                            "$ref": "#/definitions/api.Problem"
                         }
                     },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/api.Error"
+                            "$ref": "#/definitions/api.Problem"
                         }
                     },
                     "403": {
                         "description": "Forbidden",
                         "schema": {
-                            "$ref": "#/definitions/api.Error"
+                            "$ref": "#/definitions/api.Problem"
                         }
                     },
                     "404": {
                         "description": "Not Found",
                         "schema": {
-                            "$ref": "#/definitions/api.Error"
-                        }
-                    },
-                    "412": {
-                        "description": "Precondition Failed",
-                        "schema": {
-                            "$ref": "#/definitions/api.Error"
+                            "$ref": "#/definitions/api.Problem"
                         }
                     }
                 }
@@ -114,13 +108,13 @@ const docTemplate = `{
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/api.Error"
+                            "$ref": "#/definitions/api.Problem"
                         }
                     },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/api.Error"
+                            "$ref": "#/definitions/api.Problem"
                         }
                     }
                 }
@@ -160,19 +154,19 @@ const docTemplate = `{
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/api.Error"
+                            "$ref": "#/definitions/api.Problem"
                         }
                     },
                     "403": {
                         "description": "Forbidden",
                         "schema": {
-                            "$ref": "#/definitions/api.Error"
+                            "$ref": "#/definitions/api.Problem"
                         }
                     },
                     "404": {
                         "description": "Not Found",
                         "schema": {
-                            "$ref": "#/definitions/api.Error"
+                            "$ref": "#/definitions/api.Problem"
                         }
                     }
                 }
@@ -212,31 +206,25 @@ const docTemplate = `{
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/api.Error"
+                            "$ref": "#/definitions/api.Problem"
                         }
                     },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/api.Error"
+                            "$ref": "#/definitions/api.Problem"
                         }
                     },
                     "403": {
                         "description": "Forbidden",
                         "schema": {
-                            "$ref": "#/definitions/api.Error"
+                            "$ref": "#/definitions/api.Problem"
                         }
                     },
                     "404": {
                         "description": "Not Found",
                         "schema": {
-                            "$ref": "#/definitions/api.Error"
-                        }
-                    },
-                    "412": {
-                        "description": "Precondition Failed",
-                        "schema": {
-                            "$ref": "#/definitions/api.Error"
+                            "$ref": "#/definitions/api.Problem"
                         }
                     }
                 }
@@ -276,13 +264,13 @@ const docTemplate = `{
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/api.Error"
+                            "$ref": "#/definitions/api.Problem"
                         }
                     },
                     "404": {
                         "description": "Not Found",
                         "schema": {
-                            "$ref": "#/definitions/api.Error"
+                            "$ref": "#/definitions/api.Problem"
                         }
                     }
                 }
@@ -311,7 +299,7 @@ const docTemplate = `{
                     "401": {
                         "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/api.Error"
+                            "$ref": "#/definitions/api.Problem"
                         }
                     }
                 }
@@ -319,7 +307,7 @@ const docTemplate = `{
         },
         "/auth/signup": {
             "post": {
-                "description": "Create a new user. The first user created is an admin.",
+                "description": "Create a new user. The registration policy defines who can sign up.",
                 "consumes": [
                     "application/json"
                 ],
@@ -351,25 +339,25 @@ const docTemplate = `{
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/api.Error"
+                            "$ref": "#/definitions/api.Problem"
                         }
                     },
-                    "409": {
-                        "description": "Conflict",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
-                            "$ref": "#/definitions/api.Error"
+                            "$ref": "#/definitions/api.Problem"
                         }
                     },
-                    "412": {
-                        "description": "Precondition Failed",
+                    "409": {
+                        "description": "Conflict",
                         "schema": {
-                            "$ref": "#/definitions/api.Error"
+                            "$ref": "#/definitions/api.Problem"
                         }
                     },
                     "502": {
                         "description": "Bad Gateway",
                         "schema": {
-                            "$ref": "#/definitions/api.Error"
+                            "$ref": "#/definitions/api.Problem"
                         }
                     }
                 }
@@ -407,19 +395,19 @@ const docTemplate = `{
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/api.Error"
+                            "$ref": "#/definitions/api.Problem"
                         }
                     },
                     "404": {
                         "description": "Not Found",
                         "schema": {
-                            "$ref": "#/definitions/api.Error"
+                            "$ref": "#/definitions/api.Problem"
                         }
                     }
                 }
             }
         },
-        "/auth/welcome/{email}": {
+        "/auth/welcome": {
             "post": {
                 "description": "This re-sends the welcome email to the user if the user is not verified",
                 "consumes": [
@@ -454,27 +442,27 @@ const docTemplate = `{
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/api.Error"
+                            "$ref": "#/definitions/api.Problem"
                         }
                     },
                     "403": {
                         "description": "Forbidden",
                         "schema": {
-                            "$ref": "#/definitions/api.Error"
+                            "$ref": "#/definitions/api.Problem"
                         }
                     },
                     "404": {
                         "description": "Not Found",
                         "schema": {
-                            "$ref": "#/definitions/api.Error"
+                            "$ref": "#/definitions/api.Problem"
                         }
                     }
                 }
             }
         },
-        "/user/me": {
-            "delete": {
-                "description": "Delete the connected user",
+        "/invitations/{token}": {
+            "get": {
+                "description": "Get an invitation from the token sent by email",
                 "consumes": [
                     "application/json"
                 ],
@@ -482,32 +470,49 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "user"
+                    "invitation"
+                ],
+                "summary": "Get an invitation",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Invitation token sent by email",
+                        "name": "token",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "Delete the connected user",
                 "responses": {
-                    "204": {
-                        "description": "No Content",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/api.Success"
+                            "$ref": "#/definitions/models.Invitation"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/api.Error"
+                            "$ref": "#/definitions/api.Problem"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "410": {
+                        "description": "Gone",
                         "schema": {
-                            "$ref": "#/definitions/api.Error"
+                            "$ref": "#/definitions/api.Problem"
                         }
                     }
                 }
-            },
-            "patch": {
-                "description": "Update information about the connected user",
+            }
+        },
+        "/invitations/{token}/accept": {
+            "post": {
+                "description": "Accept an invitation with the account of the current user. The email address of the account must be the invited one.",
                 "consumes": [
                     "application/json"
                 ],
@@ -515,51 +520,132 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "user"
+                    "invitation"
                 ],
-                "summary": "Update information about the connected user",
+                "summary": "Accept an invitation",
                 "parameters": [
                     {
-                        "description": "User information",
-                        "name": "user",
+                        "type": "string",
+                        "description": "Invitation token sent by email",
+                        "name": "token",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.Membership"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "410": {
+                        "description": "Gone",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/invitations/{token}/signup": {
+            "post": {
+                "description": "Create an account for the invited email address and accept the invitation. The email address is considered as verified.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "invitation"
+                ],
+                "summary": "Create an account from an invitation",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Invitation token sent by email",
+                        "name": "token",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Password with confirmation",
+                        "name": "password",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.User"
+                            "$ref": "#/definitions/models.UserPasswordConfirmation"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/models.User"
+                            "$ref": "#/definitions/models.Membership"
                         }
                     },
-                    "204": {
-                        "description": "No Content",
+                    "400": {
+                        "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/api.Success"
+                            "$ref": "#/definitions/api.Problem"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
-                            "$ref": "#/definitions/api.Error"
+                            "$ref": "#/definitions/api.Problem"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
-                            "$ref": "#/definitions/api.Error"
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "410": {
+                        "description": "Gone",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
                         }
                     }
                 }
             }
         },
-        "/users": {
+        "/organizations": {
             "get": {
-                "description": "Find all users",
+                "description": "List the organizations of the current user. Platform admins get all the organizations.\nFilter with filter[field][operator]=value on name, slug, created_at and updated_at.",
                 "consumes": [
                     "application/json"
                 ],
@@ -567,9 +653,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "user"
+                    "organization"
                 ],
-                "summary": "Find all users",
+                "summary": "List the organizations",
                 "parameters": [
                     {
                         "type": "integer",
@@ -579,26 +665,21 @@ const docTemplate = `{
                     },
                     {
                         "type": "integer",
-                        "description": "Number of items per page",
+                        "description": "Number of items per page, 20 by default and 100 at most",
                         "name": "limit",
                         "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "Sort by field",
-                        "name": "sort",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Sort order (asc or desc)",
-                        "name": "order",
+                        "description": "Cursor of the page, given empty for the first page, to paginate with cursors instead of page numbers",
+                        "name": "cursor",
                         "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "Search string",
-                        "name": "search",
+                        "example": "name",
+                        "description": "Comma separated fields to sort by, descending when prefixed with a minus sign",
+                        "name": "sort",
                         "in": "query"
                     }
                 ],
@@ -606,30 +687,25 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.User"
-                            }
+                            "$ref": "#/definitions/api.Page-models_Organization"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/api.Error"
+                            "$ref": "#/definitions/api.Problem"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/api.Error"
+                            "$ref": "#/definitions/api.Problem"
                         }
                     }
                 }
-            }
-        },
-        "/users/me": {
-            "get": {
-                "description": "Get the current user",
+            },
+            "post": {
+                "description": "Create an organization. The current user becomes its owner.",
                 "consumes": [
                     "application/json"
                 ],
@@ -637,28 +713,51 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "user"
+                    "organization"
+                ],
+                "summary": "Create an organization",
+                "parameters": [
+                    {
+                        "description": "Organization information",
+                        "name": "organization",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.OrganizationInput"
+                        }
+                    }
                 ],
-                "summary": "Get the current user",
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/models.User"
+                            "$ref": "#/definitions/models.Organization"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/api.Error"
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
                         }
                     }
                 }
             }
         },
-        "/users/{id}": {
+        "/organizations/{organization_id}": {
             "get": {
-                "description": "Find a user by id",
+                "description": "Get an organization the current user is a member of",
                 "consumes": [
                     "application/json"
                 ],
@@ -666,14 +765,14 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "user"
+                    "organization"
                 ],
-                "summary": "Find a user by id",
+                "summary": "Get an organization",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "User id",
-                        "name": "id",
+                        "description": "Organization id",
+                        "name": "organization_id",
                         "in": "path",
                         "required": true
                     }
@@ -682,31 +781,31 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.User"
+                            "$ref": "#/definitions/models.Organization"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/api.Error"
+                            "$ref": "#/definitions/api.Problem"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
-                            "$ref": "#/definitions/api.Success"
+                            "$ref": "#/definitions/api.Problem"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
-                            "$ref": "#/definitions/api.Error"
+                            "$ref": "#/definitions/api.Problem"
                         }
                     }
                 }
             },
             "delete": {
-                "description": "Delete a user",
+                "description": "Delete an organization and all its memberships. The current user must be an owner of the organization.",
                 "consumes": [
                     "application/json"
                 ],
@@ -714,14 +813,14 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "user"
+                    "organization"
                 ],
-                "summary": "Delete a user",
+                "summary": "Delete an organization",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "User id",
-                        "name": "id",
+                        "description": "Organization id",
+                        "name": "organization_id",
                         "in": "path",
                         "required": true
                     }
@@ -736,25 +835,31 @@ const docTemplate = `{
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/api.Error"
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
                         }
                     },
                     "404": {
                         "description": "Not Found",
                         "schema": {
-                            "$ref": "#/definitions/api.Success"
+                            "$ref": "#/definitions/api.Problem"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/api.Error"
+                            "$ref": "#/definitions/api.Problem"
                         }
                     }
                 }
             },
             "patch": {
-                "description": "Update a user",
+                "description": "Update an organization. The current user must be an admin of the organization.",
                 "consumes": [
                     "application/json"
                 ],
@@ -762,24 +867,24 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "user"
+                    "organization"
                 ],
-                "summary": "Update a user",
+                "summary": "Update an organization",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "User id",
-                        "name": "id",
+                        "description": "Organization id",
+                        "name": "organization_id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "User information",
-                        "name": "user",
+                        "description": "Organization information",
+                        "name": "organization",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.User"
+                            "$ref": "#/definitions/models.OrganizationInput"
                         }
                     }
                 ],
@@ -787,90 +892,1493 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.User"
+                            "$ref": "#/definitions/models.Organization"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/api.Error"
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
                         }
                     },
                     "404": {
                         "description": "Not Found",
                         "schema": {
-                            "$ref": "#/definitions/api.Success"
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/api.Error"
+                            "$ref": "#/definitions/api.Problem"
                         }
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "api.Error": {
-            "type": "object",
-            "properties": {
-                "code": {
-                    "type": "string",
-                    "example": "server_code"
-                },
-                "data": {},
-                "description": {
-                    "type": "string",
-                    "example": "a server message"
-                },
-                "errors": {
-                    "type": "array",
-                    "items": {}
-                }
-            }
         },
-        "api.Success": {
-            "type": "object",
-            "properties": {
-                "code": {
-                    "type": "string",
-                    "example": "server_code"
-                },
-                "data": {},
-                "description": {
-                    "type": "string",
-                    "example": "a server message"
-                }
-            }
-        },
-        "token.AccessToken": {
-            "type": "object",
+        "/organizations/{organization_id}/invitations": {
+            "get": {
+                "description": "List the invitations of an organization. The current user must be an admin of the organization.\nFilter with filter[field][operator]=value on email, role, expires_at, accepted_at and created_at.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "invitation"
+                ],
+                "summary": "List the invitations of an organization",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Organization id",
+                        "name": "organization_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of items per page, 20 by default and 100 at most",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor of the page, given empty for the first page, to paginate with cursors instead of page numbers",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "-created_at",
+                        "description": "Comma separated fields to sort by, descending when prefixed with a minus sign",
+                        "name": "sort",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.Page-models_Invitation"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Send an invitation by email to join an organization. The current user must be an admin of the organization, only owners can invite owners.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "invitation"
+                ],
+                "summary": "Invite someone in an organization",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Organization id",
+                        "name": "organization_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Invitation",
+                        "name": "invitation",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.InvitationInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.Invitation"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/organizations/{organization_id}/invitations/{invitation_id}": {
+            "delete": {
+                "description": "Revoke a pending invitation, its link can no longer be used",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "invitation"
+                ],
+                "summary": "Revoke an invitation",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Organization id",
+                        "name": "organization_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Invitation id",
+                        "name": "invitation_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content",
+                        "schema": {
+                            "$ref": "#/definitions/api.Success"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/organizations/{organization_id}/invitations/{invitation_id}/resend": {
+            "post": {
+                "description": "Generate a new link for a pending invitation, extend its expiry and send it again by email",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "invitation"
+                ],
+                "summary": "Resend an invitation",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Organization id",
+                        "name": "organization_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Invitation id",
+                        "name": "invitation_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Invitation"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/organizations/{organization_id}/members": {
+            "get": {
+                "description": "List the members of an organization the current user is a member of.\nFilter with filter[field][operator]=value on user_id, role and created_at.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "organization"
+                ],
+                "summary": "List the members of an organization",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Organization id",
+                        "name": "organization_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of items per page, 20 by default and 100 at most",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor of the page, given empty for the first page, to paginate with cursors instead of page numbers",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "-created_at",
+                        "description": "Comma separated fields to sort by, descending when prefixed with a minus sign",
+                        "name": "sort",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.Page-models_Membership"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/organizations/{organization_id}/members/{user_id}": {
+            "delete": {
+                "description": "Remove a member from an organization. The current user must be an admin of the organization, only owners can remove another owner.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "organization"
+                ],
+                "summary": "Remove a member from an organization",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Organization id",
+                        "name": "organization_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "User id",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content",
+                        "schema": {
+                            "$ref": "#/definitions/api.Success"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "Change the role of a member. The current user must be an admin of the organization, only owners can grant or remove the owner role.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "organization"
+                ],
+                "summary": "Change the role of a member",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Organization id",
+                        "name": "organization_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "User id",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Role of the member",
+                        "name": "role",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.MembershipRole"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Membership"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/me": {
+            "delete": {
+                "description": "Delete the connected user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Delete the connected user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Entity tag of the version of the user to delete",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content",
+                        "schema": {
+                            "$ref": "#/definitions/api.Success"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "428": {
+                        "description": "Precondition Required",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "Update information about the connected user with a JSON Merge Patch or a JSON Patch\nA field is cleared when it is set to null or removed, the role and the verification cannot be updated",
+                "consumes": [
+                    "application/merge-patch+json",
+                    "application/json-patch+json",
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Update information about the connected user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Entity tag of the version of the user to update",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Fields to update",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UserUpdate"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.User"
+                        }
+                    },
+                    "204": {
+                        "description": "No Content",
+                        "schema": {
+                            "$ref": "#/definitions/api.Success"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "428": {
+                        "description": "Precondition Required",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/users": {
+            "get": {
+                "description": "Find all users. Filter with filter[field][operator]=value on email, name, first_name, last_name, role, verified, created_at and updated_at.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Find all users",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of items per page, 20 by default and 100 at most",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor of the page, given empty for the first page, to paginate with cursors instead of page numbers",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "-created_at",
+                        "description": "Comma separated fields to sort by, descending when prefixed with a minus sign",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "id,email,name",
+                        "description": "Comma separated fields to send, all by default",
+                        "name": "fields",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "memberships",
+                        "description": "Comma separated relationships to send",
+                        "name": "include",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.Page-models_User"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me": {
+            "get": {
+                "description": "Get the current user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Get the current user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Entity tag of the version of the user already known by the client",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.User"
+                        }
+                    },
+                    "304": {
+                        "description": "The user has not been modified"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}": {
+            "get": {
+                "description": "Find a user by id",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Find a user by id",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "id,email,name",
+                        "description": "Comma separated fields to send, all by default",
+                        "name": "fields",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "memberships",
+                        "description": "Comma separated relationships to send",
+                        "name": "include",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.User"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.Success"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete a user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Delete a user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Entity tag of the version of the user to delete",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content",
+                        "schema": {
+                            "$ref": "#/definitions/api.Success"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "428": {
+                        "description": "Precondition Required",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "Update a user with a JSON Merge Patch or a JSON Patch\nA field is cleared when it is set to null or removed, the editable fields depend on the role of the editor",
+                "consumes": [
+                    "application/merge-patch+json",
+                    "application/json-patch+json",
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Update a user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Entity tag of the version of the user to update",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Fields to update",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UserUpdate"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.User"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.Success"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "428": {
+                        "description": "Precondition Required",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.Problem"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "api.Code": {
+            "type": "string",
+            "enum": [
+                "bad_request",
+                "unauthorized",
+                "forbidden",
+                "not_found",
+                "conflict",
+                "gone",
+                "precondition_failed",
+                "precondition_required",
+                "internal_error",
+                "not_implemented",
+                "bad_gateway",
+                "unsupported_media_type",
+                "invalid_body",
+                "validation_failed",
+                "invalid_patch",
+                "patch_test_failed",
+                "resource_modified",
+                "invalid_filter",
+                "invalid_selection",
+                "not_authenticated",
+                "invalid_credentials",
+                "invalid_token",
+                "account_not_verified",
+                "account_already_verified",
+                "account_exists",
+                "registration_denied",
+                "platform_admin_required",
+                "user_not_found",
+                "invalid_organization_id",
+                "organization_not_found",
+                "not_organization_member",
+                "organization_role_required",
+                "owner_role_required",
+                "member_not_found",
+                "organization_slug_taken",
+                "already_member",
+                "last_owner",
+                "invitation_not_found",
+                "invitation_exists",
+                "invitation_accepted",
+                "invitation_expired",
+                "invitation_email_mismatch"
+            ],
+            "x-enum-varnames": [
+                "CodeBadRequest",
+                "CodeUnauthorized",
+                "CodeForbidden",
+                "CodeNotFound",
+                "CodeConflict",
+                "CodeGone",
+                "CodePreconditionFailed",
+                "CodePreconditionRequired",
+                "CodeInternal",
+                "CodeNotImplemented",
+                "CodeBadGateway",
+                "CodeUnsupportedMediaType",
+                "CodeInvalidBody",
+                "CodeValidationFailed",
+                "CodeInvalidPatch",
+                "CodePatchTestFailed",
+                "CodeResourceModified",
+                "CodeInvalidFilter",
+                "CodeInvalidSelection",
+                "CodeNotAuthenticated",
+                "CodeInvalidCredentials",
+                "CodeInvalidToken",
+                "CodeAccountNotVerified",
+                "CodeAccountAlreadyVerified",
+                "CodeAccountExists",
+                "CodeRegistrationDenied",
+                "CodePlatformAdminRequired",
+                "CodeUserNotFound",
+                "CodeInvalidOrganizationId",
+                "CodeOrganizationNotFound",
+                "CodeNotOrganizationMember",
+                "CodeOrganizationRoleRequired",
+                "CodeOwnerRoleRequired",
+                "CodeMemberNotFound",
+                "CodeOrganizationSlugTaken",
+                "CodeAlreadyMember",
+                "CodeLastOwner",
+                "CodeInvitationNotFound",
+                "CodeInvitationExists",
+                "CodeInvitationAccepted",
+                "CodeInvitationExpired",
+                "CodeInvitationEmailMismatch"
+            ]
+        },
+        "api.FieldError": {
+            "description": "Validation error of a field of the request body",
+            "type": "object",
+            "properties": {
+                "field": {
+                    "description": "Field is the json name of the field, nested fields are separated by a dot",
+                    "type": "string",
+                    "example": "password"
+                },
+                "message": {
+                    "description": "Message explains the error",
+                    "type": "string",
+                    "example": "password must be at least 8 characters long"
+                },
+                "param": {
+                    "description": "Param is the parameter of the rule, if any",
+                    "type": "string",
+                    "example": "8"
+                },
+                "rule": {
+                    "description": "Rule is the validation rule which failed",
+                    "type": "string",
+                    "example": "min"
+                }
+            }
+        },
+        "api.Page-models_Invitation": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Invitation"
+                    }
+                },
+                "limit": {
+                    "type": "integer",
+                    "example": 20
+                },
+                "next": {
+                    "type": "string",
+                    "example": "/api/users?limit=20\u0026page=2"
+                },
+                "next_cursor": {
+                    "description": "NextCursor is the cursor of the next page, it is only set when the list is paginated with cursors",
+                    "type": "string"
+                },
+                "page": {
+                    "description": "Page is the page number, it is omitted when the list is paginated with cursors",
+                    "type": "integer",
+                    "example": 1
+                },
+                "prev": {
+                    "type": "string"
+                },
+                "total": {
+                    "type": "integer",
+                    "example": 42
+                }
+            }
+        },
+        "api.Page-models_Membership": {
+            "type": "object",
             "properties": {
-                "access_token": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Membership"
+                    }
+                },
+                "limit": {
+                    "type": "integer",
+                    "example": 20
+                },
+                "next": {
+                    "type": "string",
+                    "example": "/api/users?limit=20\u0026page=2"
+                },
+                "next_cursor": {
+                    "description": "NextCursor is the cursor of the next page, it is only set when the list is paginated with cursors",
+                    "type": "string"
+                },
+                "page": {
+                    "description": "Page is the page number, it is omitted when the list is paginated with cursors",
+                    "type": "integer",
+                    "example": 1
+                },
+                "prev": {
+                    "type": "string"
+                },
+                "total": {
+                    "type": "integer",
+                    "example": 42
+                }
+            }
+        },
+        "api.Page-models_Organization": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Organization"
+                    }
+                },
+                "limit": {
+                    "type": "integer",
+                    "example": 20
+                },
+                "next": {
+                    "type": "string",
+                    "example": "/api/users?limit=20\u0026page=2"
+                },
+                "next_cursor": {
+                    "description": "NextCursor is the cursor of the next page, it is only set when the list is paginated with cursors",
+                    "type": "string"
+                },
+                "page": {
+                    "description": "Page is the page number, it is omitted when the list is paginated with cursors",
+                    "type": "integer",
+                    "example": 1
+                },
+                "prev": {
+                    "type": "string"
+                },
+                "total": {
+                    "type": "integer",
+                    "example": 42
+                }
+            }
+        },
+        "api.Page-models_User": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.User"
+                    }
+                },
+                "limit": {
+                    "type": "integer",
+                    "example": 20
+                },
+                "next": {
+                    "type": "string",
+                    "example": "/api/users?limit=20\u0026page=2"
+                },
+                "next_cursor": {
+                    "description": "NextCursor is the cursor of the next page, it is only set when the list is paginated with cursors",
+                    "type": "string"
+                },
+                "page": {
+                    "description": "Page is the page number, it is omitted when the list is paginated with cursors",
+                    "type": "integer",
+                    "example": 1
+                },
+                "prev": {
+                    "type": "string"
+                },
+                "total": {
+                    "type": "integer",
+                    "example": 42
+                }
+            }
+        },
+        "api.Problem": {
+            "description": "Error response as defined by RFC 7807, sent as application/problem+json",
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "Code is the stable and machine-readable code of the problem",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/api.Code"
+                        }
+                    ],
+                    "example": "user_not_found"
+                },
+                "detail": {
+                    "description": "Detail explains this occurrence of the problem",
+                    "type": "string",
+                    "example": "user not found"
+                },
+                "errors": {
+                    "description": "Errors are the invalid fields of the request body, if any",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.FieldError"
+                    }
+                },
+                "instance": {
+                    "description": "Instance is the path of the request",
+                    "type": "string",
+                    "example": "/api/users/6b0f8e1e-6b4f-4b8a-9e6a-0b8f0b8f0b8f"
+                },
+                "request_id": {
+                    "description": "RequestId identifies the request in the logs",
+                    "type": "string",
+                    "example": "c0a8012e-7f7c-4b9a-8c6a-2d5b7f3e9a10"
+                },
+                "status": {
+                    "description": "Status is the http status code",
+                    "type": "integer",
+                    "example": 404
+                },
+                "title": {
+                    "description": "Title is a short summary of the kind of problem",
+                    "type": "string",
+                    "example": "User not found"
+                },
+                "type": {
+                    "description": "Type identifies the kind of problem",
+                    "type": "string",
+                    "example": "urn:problem-type:user_not_found"
+                }
+            }
+        },
+        "api.Success": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "server_code"
+                },
+                "data": {},
+                "description": {
+                    "type": "string",
+                    "example": "a server message"
+                }
+            }
+        },
+        "models.Invitation": {
+            "description": "Invitation sent by email to join an organization",
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "description": "Timestamps",
+                    "type": "string"
+                },
+                "email": {
                     "type": "string"
                 },
                 "expires_at": {
-                    "type": "integer"
+                    "description": "Invitation status",
+                    "type": "string"
                 },
-                "expires_in": {
-                    "type": "integer"
+                "id": {
+                    "type": "string"
                 },
-                "refresh_expires_at": {
-                    "type": "integer"
+                "invited_by_id": {
+                    "type": "string"
                 },
-                "refresh_expires_in": {
-                    "type": "integer"
+                "organization": {
+                    "description": "Relations",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.Organization"
+                        }
+                    ]
                 },
-                "refresh_token": {
+                "organization_id": {
                     "type": "string"
                 },
-                "token_type": {
+                "role": {
+                    "$ref": "#/definitions/models.OrganizationRole"
+                },
+                "token": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.InvitationInput": {
+            "description": "Invitation model used to invite someone in an organization",
+            "type": "object",
+            "required": [
+                "email",
+                "role"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "example": "my-colleague@gmail.com"
+                },
+                "role": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.OrganizationRole"
+                        }
+                    ],
+                    "example": "member"
+                }
+            }
+        },
+        "models.Membership": {
+            "description": "Membership model, it links a user to an organization with a role",
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "description": "Timestamps",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "organization": {
+                    "$ref": "#/definitions/models.Organization"
+                },
+                "organization_id": {
+                    "type": "string"
+                },
+                "role": {
+                    "$ref": "#/definitions/models.OrganizationRole"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user": {
+                    "description": "Relations",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.User"
+                        }
+                    ]
+                },
+                "user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.MembershipRole": {
+            "description": "Membership role model used to change the role of a member",
+            "type": "object",
+            "required": [
+                "role"
+            ],
+            "properties": {
+                "role": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.OrganizationRole"
+                        }
+                    ],
+                    "example": "member"
+                }
+            }
+        },
+        "models.Organization": {
+            "description": "Organization model, it is the tenant owning the resources",
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "description": "Timestamps",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "Organization information",
+                    "type": "string"
+                },
+                "memberships": {
+                    "description": "Members of the organization",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Membership"
+                    }
+                },
+                "name": {
+                    "type": "string"
+                },
+                "slug": {
+                    "type": "string"
+                },
+                "updated_at": {
                     "type": "string"
                 }
             }
         },
+        "models.OrganizationInput": {
+            "description": "Organization model used for creation and update",
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "example": "My company"
+                },
+                "slug": {
+                    "type": "string",
+                    "example": "my-company"
+                }
+            }
+        },
+        "models.OrganizationRole": {
+            "type": "string",
+            "enum": [
+                "owner",
+                "admin",
+                "member"
+            ],
+            "x-enum-varnames": [
+                "OrganizationRoleOwner",
+                "OrganizationRoleAdmin",
+                "OrganizationRoleMember"
+            ]
+        },
         "models.Role": {
             "type": "string",
             "enum": [
@@ -899,6 +2407,18 @@ const docTemplate = `{
                 "last_name": {
                     "type": "string"
                 },
+                "locale": {
+                    "description": "Language of the emails and of the responses, the Accept-Language header is used if it is empty",
+                    "type": "string",
+                    "example": "fr"
+                },
+                "memberships": {
+                    "description": "Organizations the user belongs to",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Membership"
+                    }
+                },
                 "name": {
                     "type": "string"
                 },
@@ -931,17 +2451,17 @@ const docTemplate = `{
             "description": "User password confirmation model used for password reset",
             "type": "object",
             "required": [
-                "confirm",
-                "password"
+                "password",
+                "password_confirmation"
             ],
             "properties": {
-                "confirm": {
+                "password": {
                     "type": "string",
+                    "minLength": 8,
                     "example": "strong-password"
                 },
-                "password": {
+                "password_confirmation": {
                     "type": "string",
-                    "minLength": 8,
                     "example": "strong-password"
                 }
             }
@@ -969,26 +2489,22 @@ const docTemplate = `{
             "type": "object",
             "required": [
                 "email",
-                "first_name",
-                "last_name",
                 "password",
-                "password_confirm"
+                "password_confirmation"
             ],
             "properties": {
                 "email": {
                     "type": "string"
                 },
-                "first_name": {
-                    "type": "string"
-                },
-                "last_name": {
-                    "type": "string"
+                "locale": {
+                    "type": "string",
+                    "example": "fr"
                 },
                 "password": {
                     "type": "string",
                     "minLength": 8
                 },
-                "password_confirm": {
+                "password_confirmation": {
                     "type": "string"
                 }
             }
@@ -1004,6 +2520,73 @@ const docTemplate = `{
                     "type": "string"
                 }
             }
+        },
+        "models.UserUpdate": {
+            "description": "User update model, sent as a JSON Merge Patch or a JSON Patch",
+            "type": "object",
+            "required": [
+                "role"
+            ],
+            "properties": {
+                "first_name": {
+                    "type": "string",
+                    "example": "John"
+                },
+                "last_name": {
+                    "type": "string",
+                    "example": "Doe"
+                },
+                "locale": {
+                    "type": "string",
+                    "example": "fr"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "John Doe"
+                },
+                "role": {
+                    "enum": [
+                        "admin",
+                        "user"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.Role"
+                        }
+                    ],
+                    "example": "user"
+                },
+                "verified": {
+                    "type": "boolean",
+                    "example": true
+                }
+            }
+        },
+        "token.AccessToken": {
+            "type": "object",
+            "properties": {
+                "access_token": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "integer"
+                },
+                "expires_in": {
+                    "type": "integer"
+                },
+                "refresh_expires_at": {
+                    "type": "integer"
+                },
+                "refresh_expires_in": {
+                    "type": "integer"
+                },
+                "refresh_token": {
+                    "type": "string"
+                },
+                "token_type": {
+                    "type": "string"
+                }
+            }
         }
     }
 }`
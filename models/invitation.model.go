@@ -0,0 +1,73 @@
+package models
+
+import (
+	"database/sql"
+	"github.com/go-api-template/go-backend/modules/config"
+	api "github.com/go-api-template/go-backend/modules/utils/api"
+	"github.com/google/uuid"
+	"time"
+)
+
+// Invitation model
+//
+//	@description	Invitation sent by email to join an organization
+type Invitation struct {
+	ID             uuid.UUID        `json:"id"              gorm:"type:uuid;default:uuid_generate_v4();primary_key"`
+	OrganizationID uuid.UUID        `json:"organization_id" gorm:"type:uuid;not null;index"`
+	Email          string           `json:"email"           gorm:"not null;index"`
+	Role           OrganizationRole `json:"role"            gorm:"type:varchar(255);not null"`
+	Token          string           `json:"token,omitempty" gorm:"uniqueIndex;not null"`
+	InvitedByID    uuid.UUID        `json:"invited_by_id"   gorm:"type:uuid;not null"`
+
+	// Invitation status
+	ExpiresAt  time.Time    `json:"expires_at"  gorm:"not null"`
+	AcceptedAt sql.NullTime `json:"-"`
+
+	// Relations
+	Organization *Organization `json:"organization,omitempty" gorm:"constraint:OnDelete:CASCADE"`
+	InvitedBy    *User         `json:"-"`
+
+	// Timestamps
+	CreatedAt time.Time `json:"created_at" gorm:"not null"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"not null"`
+}
+
+// InvitationFields are the fields which can be used to filter and sort a list of invitations
+var InvitationFields = api.Fields{
+	"email":       {Column: "invitations.email", Operators: api.StringOperators, Sortable: true},
+	"role":        {Column: "invitations.role", Operators: api.EqualityOperators, Sortable: true},
+	"expires_at":  {Column: "invitations.expires_at", Operators: api.RangeOperators, Sortable: true, Parse: api.ParseTime},
+	"accepted_at": {Column: "invitations.accepted_at", Operators: []api.Operator{api.OperatorNull, api.OperatorLt, api.OperatorGt}, Parse: api.ParseTime},
+	"created_at":  {Column: "invitations.created_at", Operators: api.RangeOperators, Sortable: true, Parse: api.ParseTime},
+}
+
+// InvitationInput model
+//
+//	@description	Invitation model used to invite someone in an organization
+type InvitationInput struct {
+	Email string           `json:"email" binding:"required,email" example:"my-colleague@gmail.com"`
+	Role  OrganizationRole `json:"role"  binding:"required"       example:"member"`
+}
+
+// IsExpired checks if the invitation can no longer be accepted
+func (i *Invitation) IsExpired() bool {
+	return time.Now().After(i.ExpiresAt)
+}
+
+// IsAccepted checks if the invitation has already been accepted
+func (i *Invitation) IsAccepted() bool {
+	return i.AcceptedAt.Valid
+}
+
+// Response returns the invitation without the token
+func (i *Invitation) Response() Invitation {
+	invitation := *i
+	invitation.InvitedBy = nil
+
+	// Add the token if the app is in debug mode
+	if !config.Config.App.Debug {
+		invitation.Token = ""
+	}
+
+	return invitation
+}
@@ -0,0 +1,39 @@
+package models
+
+import (
+	api "github.com/go-api-template/go-backend/modules/utils/api"
+	"github.com/google/uuid"
+	"time"
+)
+
+// Membership model
+//
+//	@description	Membership model, it links a user to an organization with a role
+type Membership struct {
+	ID             uuid.UUID        `json:"id"              gorm:"type:uuid;default:uuid_generate_v4();primary_key"`
+	UserID         uuid.UUID        `json:"user_id"         gorm:"type:uuid;not null;uniqueIndex:idx_membership_user_organization"`
+	OrganizationID uuid.UUID        `json:"organization_id" gorm:"type:uuid;not null;uniqueIndex:idx_membership_user_organization;index"`
+	Role           OrganizationRole `json:"role"            gorm:"type:varchar(255);not null"`
+
+	// Relations
+	User         *User         `json:"user,omitempty"`
+	Organization *Organization `json:"organization,omitempty"`
+
+	// Timestamps
+	CreatedAt time.Time `json:"created_at" gorm:"not null"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"not null"`
+}
+
+// MembershipFields are the fields which can be used to filter and sort a list of members
+var MembershipFields = api.Fields{
+	"user_id":    {Column: "memberships.user_id", Operators: api.EqualityOperators, Parse: api.ParseUUID},
+	"role":       {Column: "memberships.role", Operators: api.EqualityOperators, Sortable: true},
+	"created_at": {Column: "memberships.created_at", Operators: api.RangeOperators, Sortable: true, Parse: api.ParseTime},
+}
+
+// MembershipRole model
+//
+//	@description	Membership role model used to change the role of a member
+type MembershipRole struct {
+	Role OrganizationRole `json:"role" binding:"required" example:"member"`
+}
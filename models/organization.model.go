@@ -0,0 +1,42 @@
+package models
+
+import (
+	api "github.com/go-api-template/go-backend/modules/utils/api"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"time"
+)
+
+// Organization model
+//
+//	@description	Organization model, it is the tenant owning the resources
+type Organization struct {
+	// Organization information
+	ID   uuid.UUID `json:"id"   gorm:"type:uuid;default:uuid_generate_v4();primary_key"`
+	Name string    `json:"name" gorm:"not null"`
+	Slug string    `json:"slug" gorm:"uniqueIndex;not null"`
+
+	// Members of the organization
+	Memberships []Membership `json:"memberships,omitempty" gorm:"constraint:OnDelete:CASCADE"`
+
+	// Timestamps
+	CreatedAt time.Time      `json:"created_at" gorm:"not null"`
+	UpdatedAt time.Time      `json:"updated_at" gorm:"not null"`
+	DeletedAt gorm.DeletedAt `json:"-"          gorm:"index"`
+}
+
+// OrganizationFields are the fields which can be used to filter and sort a list of organizations
+var OrganizationFields = api.Fields{
+	"name":       {Column: "organizations.name", Operators: api.StringOperators, Sortable: true},
+	"slug":       {Column: "organizations.slug", Operators: api.StringOperators, Sortable: true},
+	"created_at": {Column: "organizations.created_at", Operators: api.RangeOperators, Sortable: true, Parse: api.ParseTime},
+	"updated_at": {Column: "organizations.updated_at", Operators: api.RangeOperators, Sortable: true, Parse: api.ParseTime},
+}
+
+// OrganizationInput model
+//
+//	@description	Organization model used for creation and update
+type OrganizationInput struct {
+	Name string `json:"name" binding:"required" example:"My company"`
+	Slug string `json:"slug"                    example:"my-company"`
+}
@@ -3,10 +3,14 @@ package models
 import "fmt"
 
 // Role given to a user
+// It is a platform wide role, the role of a user inside
+// an organization is defined by the OrganizationRole
 type Role string
 
 // Enum of types of roles
 const (
+	// RoleAdmin is the platform administrator
+	// It grants access to every user and every organization
 	RoleAdmin Role = "admin"
 	RoleUser  Role = "user"
 )
@@ -36,7 +40,69 @@ func (r Role) IsAdmin() bool {
 	return r == RoleAdmin
 }
 
+// IsPlatformAdmin checks if the role is the platform administrator
+func (r Role) IsPlatformAdmin() bool {
+	return r.IsAdmin()
+}
+
 // IsUser checks if the role is user
 func (r Role) IsUser() bool {
 	return r == RoleUser
 }
+
+// OrganizationRole given to a member of an organization
+type OrganizationRole string
+
+// Enum of types of organization roles
+const (
+	OrganizationRoleOwner  OrganizationRole = "owner"
+	OrganizationRoleAdmin  OrganizationRole = "admin"
+	OrganizationRoleMember OrganizationRole = "member"
+)
+
+// organizationRoleRanks orders the organization roles
+// A role with a higher rank has all the permissions of the roles below
+var organizationRoleRanks = map[OrganizationRole]int{
+	OrganizationRoleMember: 1,
+	OrganizationRoleAdmin:  2,
+	OrganizationRoleOwner:  3,
+}
+
+// String returns the string representation of the organization role
+func (r OrganizationRole) String() string {
+	return string(r)
+}
+
+// ParseOrganizationRole parses a string into an OrganizationRole
+func ParseOrganizationRole(s string) (r OrganizationRole, err error) {
+	r = OrganizationRole(s)
+	_, ok := organizationRoleRanks[r]
+	if !ok {
+		return r, fmt.Errorf(`cannot parse:[%s] as OrganizationRole`, s)
+	}
+	return r, nil
+}
+
+// IsOwner checks if the organization role is owner
+func (r OrganizationRole) IsOwner() bool {
+	return r == OrganizationRoleOwner
+}
+
+// IsAdmin checks if the organization role is admin or owner
+func (r OrganizationRole) IsAdmin() bool {
+	return r.Covers(OrganizationRoleAdmin)
+}
+
+// IsMember checks if the organization role is a valid role
+func (r OrganizationRole) IsMember() bool {
+	return r.Covers(OrganizationRoleMember)
+}
+
+// Covers checks if the organization role has at least the permissions of the given role
+func (r OrganizationRole) Covers(role OrganizationRole) bool {
+	rank, ok := organizationRoleRanks[r]
+	if !ok {
+		return false
+	}
+	return rank >= organizationRoleRanks[role]
+}
@@ -0,0 +1,96 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// tenantField is the name of the field which identifies
+// the organization owning a row
+const tenantField = "OrganizationID"
+
+// organizationKey is the key of the current organization in a context
+type organizationKey struct{}
+
+// WithOrganization returns a copy of the context bound to the organization
+// The queries run with this context only read and write the rows owned by the organization, see TenantPlugin
+func WithOrganization(ctx context.Context, organizationID uuid.UUID) context.Context {
+	return context.WithValue(ctx, organizationKey{}, organizationID)
+}
+
+// OrganizationFromContext returns the organization the context is bound to
+func OrganizationFromContext(ctx context.Context) (uuid.UUID, bool) {
+	if ctx == nil {
+		return uuid.Nil, false
+	}
+	organizationID, ok := ctx.Value(organizationKey{}).(uuid.UUID)
+	return organizationID, ok
+}
+
+// ScopeOrganization restricts a query to the rows owned by the organization
+// Models which are not owned by an organization, meaning that they do not
+// declare an OrganizationID field, are left untouched
+func ScopeOrganization(organizationID uuid.UUID) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		// The model is not assigned yet when the scopes are executed
+		model := db.Statement.Model
+		if model == nil {
+			model = db.Statement.Dest
+		}
+		if model == nil {
+			return db
+		}
+
+		// Parse the model to find the tenant column
+		if err := db.Statement.Parse(model); err != nil {
+			_ = db.AddError(err)
+			return db
+		}
+		field := db.Statement.Schema.LookUpField(tenantField)
+		if field == nil {
+			return db
+		}
+
+		return db.Where(db.Statement.Quote(db.Statement.Table+"."+field.DBName)+" = ?", organizationID)
+	}
+}
+
+// TenantPlugin restricts the queries to the organization of their context, it is added with gormDb.Use
+// The queries, updates and deletes of the tenant-owned models run with a context bound by WithOrganization
+// are filtered on the organization, so a query which forgets ScopeOrganization cannot leak the rows of another one.
+// The raw queries are not filtered
+type TenantPlugin struct{}
+
+// Name returns the name of the plugin
+func (TenantPlugin) Name() string {
+	return "tenant"
+}
+
+// Initialize registers the callbacks which filter the queries
+func (p TenantPlugin) Initialize(db *gorm.DB) error {
+	callback := db.Callback()
+	return errors.Join(
+		callback.Query().Before("gorm:query").Register("tenant:query", p.scope),
+		callback.Update().Before("gorm:update").Register("tenant:update", p.scope),
+		callback.Delete().Before("gorm:delete").Register("tenant:delete", p.scope),
+		callback.Row().Before("gorm:row").Register("tenant:row", p.scope),
+	)
+}
+
+// scope adds the condition on the organization of the context to the statement
+func (TenantPlugin) scope(db *gorm.DB) {
+	organizationID, ok := OrganizationFromContext(db.Statement.Context)
+	if !ok || db.Statement.Schema == nil {
+		return
+	}
+	field := db.Statement.Schema.LookUpField(tenantField)
+	if field == nil {
+		return
+	}
+	db.Statement.AddClause(clause.Where{Exprs: []clause.Expression{
+		clause.Eq{Column: clause.Column{Table: db.Statement.Table, Name: field.DBName}, Value: organizationID},
+	}})
+}
@@ -4,6 +4,7 @@ package models
 import (
 	"database/sql"
 	"github.com/go-api-template/go-backend/modules/config"
+	api "github.com/go-api-template/go-backend/modules/utils/api"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 	"time"
@@ -22,6 +23,9 @@ type User struct {
 	Password  string    `json:"-"          gorm:"not null"`
 	Role      Role      `json:"role"       gorm:"type:varchar(255);not null"`
 
+	// Language of the emails and of the responses, the Accept-Language header is used if it is empty
+	Locale string `json:"locale" gorm:"type:varchar(16);not null;default:''" example:"fr"`
+
 	// User status
 	Verified          bool   `json:"-"     gorm:"not null"`
 	VerificationToken string `json:"verification_token,omitempty"`
@@ -30,19 +34,36 @@ type User struct {
 	ResetToken  string       `json:"reset_token,omitempty"`
 	LastResetAt sql.NullTime `json:"-"`
 
+	// Organizations the user belongs to
+	Memberships []Membership `json:"memberships,omitempty" gorm:"constraint:OnDelete:CASCADE"`
+
 	// Timestamps
 	CreatedAt time.Time      `json:"-"      gorm:"not null"`
 	UpdatedAt time.Time      `json:"-"      gorm:"not null"`
 	DeletedAt gorm.DeletedAt `json:"-"      gorm:"index"`
 }
 
+// UserFields are the fields which can be used to filter and sort a list of users
+var UserFields = api.Fields{
+	"email":      {Column: "users.email", Operators: api.StringOperators, Sortable: true},
+	"name":       {Column: "users.name", Operators: api.StringOperators, Sortable: true},
+	"first_name": {Column: "users.first_name", Operators: api.StringOperators, Sortable: true},
+	"last_name":  {Column: "users.last_name", Operators: api.StringOperators, Sortable: true},
+	"role":       {Column: "users.role", Operators: api.EqualityOperators, Sortable: true},
+	"verified":   {Column: "users.verified", Operators: []api.Operator{api.OperatorEq}, Parse: api.ParseBool},
+	"locale":     {Column: "users.locale", Operators: api.EqualityOperators},
+	"created_at": {Column: "users.created_at", Operators: api.RangeOperators, Sortable: true, Parse: api.ParseTime},
+	"updated_at": {Column: "users.updated_at", Operators: api.RangeOperators, Sortable: true, Parse: api.ParseTime},
+}
+
 // UserSignUp model
 //
 //	@description	User sign up model used for registration
 type UserSignUp struct {
 	Email                string `json:"email"                 binding:"required"`
 	Password             string `json:"password"              binding:"required,min=8"`
-	PasswordConfirmation string `json:"password_confirmation" binding:"required"`
+	PasswordConfirmation string `json:"password_confirmation" binding:"required,confirms=password"`
+	Locale               string `json:"locale"                binding:"omitempty,locale" example:"fr"`
 }
 
 // UserSignIn model
@@ -72,7 +93,53 @@ type UserToken struct {
 //	@description	User password confirmation model used for password reset
 type UserPasswordConfirmation struct {
 	Password             string `json:"password" binding:"required,min=8" example:"strong-password"`
-	PasswordConfirmation string `json:"password_confirmation"  binding:"required" example:"strong-password"`
+	PasswordConfirmation string `json:"password_confirmation"  binding:"required,confirms=password" example:"strong-password"`
+}
+
+// UserUpdate model
+// It holds the fields of a user which can be updated, the editable ones depend on the role of the editor
+//
+//	@description	User update model, sent as a JSON Merge Patch or a JSON Patch
+type UserUpdate struct {
+	Name      string `json:"name"       example:"John Doe"`
+	FirstName string `json:"first_name" example:"John"`
+	LastName  string `json:"last_name"  example:"Doe"`
+	Locale    string `json:"locale"     binding:"omitempty,locale" example:"fr"`
+	Role      Role   `json:"role"       binding:"required,oneof=admin user" example:"user"`
+	Verified  bool   `json:"verified"   example:"true"`
+}
+
+// UserUpdateFields are the fields of a user which can be updated, by role of the editor
+var UserUpdateFields = map[Role][]string{
+	RoleUser:  {"name", "first_name", "last_name", "locale"},
+	RoleAdmin: {"name", "first_name", "last_name", "locale", "role", "verified"},
+}
+
+// NewUserUpdate returns the current state of the updatable fields of a user
+func NewUserUpdate(u *User) UserUpdate {
+	return UserUpdate{
+		Name:      u.Name,
+		FirstName: u.FirstName,
+		LastName:  u.LastName,
+		Locale:    u.Locale,
+		Role:      u.Role,
+		Verified:  u.Verified,
+	}
+}
+
+// Apply sets the updated fields to the user
+func (p UserUpdate) Apply(u *User) {
+	u.Name = p.Name
+	u.FirstName = p.FirstName
+	u.LastName = p.LastName
+	u.Locale = p.Locale
+	u.Role = p.Role
+	u.Verified = p.Verified
+}
+
+// ETag returns the entity tag of the current version of the user
+func (u *User) ETag() string {
+	return api.NewETag(u.ID.String(), u.UpdatedAt)
 }
 
 // SetResetToken sets the reset token
@@ -92,6 +159,9 @@ func (u *User) Response() User {
 		FirstName: u.FirstName,
 		LastName:  u.LastName,
 		Role:      u.Role,
+		Locale:    u.Locale,
+
+		Memberships: u.Memberships,
 	}
 
 	// Add the verification code if the app is in debug mode
@@ -0,0 +1,238 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"errors"
+	"github.com/go-api-template/go-backend/modules/config"
+	"github.com/go-redis/redis/v8"
+	"github.com/rs/zerolog/log"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// Cache stores values in Redis
+// The values are encoded with gob, so the fields hidden from the json responses are kept
+type Cache struct {
+	redis *redis.Client
+	calls group
+}
+
+var (
+	// c is the singleton instance of the cache
+	c *Cache
+	// Prevent multiple initialization
+	once sync.Once
+)
+
+// Prefixes of the Redis keys
+const (
+	keyPrefix     = "cache:"
+	tagPrefix     = "cache-tag:"
+	versionPrefix = "cache-version:"
+)
+
+// versionTTL is how long the version of a deleted key is kept
+// It must outlive the loads which started before the deletion
+const versionTTL = time.Hour
+
+// NewCache returns the cache stored in Redis
+// It is a singleton and can be called multiple times
+// but will only return the same instance
+func NewCache(redis *redis.Client) *Cache {
+	once.Do(func() {
+		c = &Cache{redis: redis}
+	})
+	return c
+}
+
+// TTL returns how long the values are kept by default
+func TTL() time.Duration {
+	return time.Duration(config.Config.Cache.TTL) * time.Second
+}
+
+// enabled returns false when the cache is disabled or not initialized
+// All the methods can then be called, but nothing is stored
+func (c *Cache) enabled() bool {
+	return c != nil && c.redis != nil && config.Config.Cache.Enable
+}
+
+// Get reads a value from the cache
+// It returns false if the value is not in the cache
+func Get[T any](ctx context.Context, c *Cache, key string) (value T, found bool, err error) {
+	if !c.enabled() {
+		return
+	}
+	data, err := c.redis.Get(ctx, keyPrefix+key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return value, false, nil
+	}
+	if err != nil {
+		return
+	}
+	if err = gob.NewDecoder(bytes.NewReader(data)).Decode(&value); err != nil {
+		return
+	}
+	return value, true, nil
+}
+
+// Set writes a value in the cache for the ttl
+// The tags group the values which are invalidated together
+func Set[T any](ctx context.Context, c *Cache, key string, value T, ttl time.Duration, tags ...string) error {
+	if !c.enabled() {
+		return nil
+	}
+	data, err := encode(value)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.redis.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		c.write(ctx, pipe, key, data, ttl, tags)
+		return nil
+	})
+	return err
+}
+
+// Remember reads a value from the cache, or loads and stores it if it is not in the cache
+// The concurrent loads of a same key are merged, so a missing value is loaded only once:
+// the load is not canceled with the context of the caller which runs it, and each caller gets its own copy.
+// A value deleted from the cache while it is loaded is not stored, as it may be stale.
+// The zero values, such as the nil pointers, are not stored.
+// The value is loaded without cache when Redis is unavailable
+func Remember[T any](ctx context.Context, c *Cache, key string, ttl time.Duration, tags []string, load func(ctx context.Context) (T, error)) (T, error) {
+	if !c.enabled() {
+		return load(ctx)
+	}
+	value, found, err := Get[T](ctx, c, key)
+	if err != nil {
+		log.Ctx(ctx).Warn().Err(err).Str("key", key).Msg("Reading the cache")
+	}
+	if found {
+		return value, nil
+	}
+
+	shared, err := c.calls.do(ctx, key, func() (any, error) {
+		ctx := context.WithoutCancel(ctx)
+
+		// The version of the key changes when it is deleted
+		version, versionErr := c.version(ctx, c.redis, key)
+
+		value, err := load(ctx)
+		if err != nil || reflect.ValueOf(&value).Elem().IsZero() {
+			return nil, err
+		}
+		data, err := encode(value)
+		if err != nil {
+			return nil, err
+		}
+		if versionErr == nil {
+			versionErr = c.setIfCurrent(ctx, key, version, data, ttl, tags)
+		}
+		if versionErr != nil {
+			log.Ctx(ctx).Warn().Err(versionErr).Str("key", key).Msg("Writing the cache")
+		}
+		return data, nil
+	})
+	if err != nil || shared == nil {
+		return value, err
+	}
+	err = gob.NewDecoder(bytes.NewReader(shared.([]byte))).Decode(&value)
+	return value, err
+}
+
+// setIfCurrent writes an encoded value unless its key has been deleted since the version was read
+func (c *Cache) setIfCurrent(ctx context.Context, key string, version int64, data []byte, ttl time.Duration, tags []string) error {
+	err := c.redis.Watch(ctx, func(tx *redis.Tx) error {
+		current, err := c.version(ctx, tx, key)
+		if err != nil || current != version {
+			return err
+		}
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			c.write(ctx, pipe, key, data, ttl, tags)
+			return nil
+		})
+		return err
+	}, versionPrefix+key)
+	if errors.Is(err, redis.TxFailedErr) {
+		// The key has been deleted while it was written
+		return nil
+	}
+	return err
+}
+
+// write queues the commands writing an encoded value and its tags
+func (c *Cache) write(ctx context.Context, pipe redis.Pipeliner, key string, data []byte, ttl time.Duration, tags []string) {
+	pipe.Set(ctx, keyPrefix+key, data, ttl)
+	for _, tag := range tags {
+		// The tag lives as long as its longest value
+		pipe.SAdd(ctx, tagPrefix+tag, key)
+		pipe.Expire(ctx, tagPrefix+tag, max(ttl, c.tagTTL(ctx, tag)))
+	}
+}
+
+// version returns the number of times a key has been deleted
+func (c *Cache) version(ctx context.Context, redisCmd redis.Cmdable, key string) (int64, error) {
+	version, err := redisCmd.Get(ctx, versionPrefix+key).Int64()
+	if errors.Is(err, redis.Nil) {
+		return 0, nil
+	}
+	return version, err
+}
+
+// encode encodes a value with gob
+func encode(value any) ([]byte, error) {
+	var data bytes.Buffer
+	if err := gob.NewEncoder(&data).Encode(value); err != nil {
+		return nil, err
+	}
+	return data.Bytes(), nil
+}
+
+// Delete removes values from the cache
+func (c *Cache) Delete(ctx context.Context, keys ...string) error {
+	if !c.enabled() || len(keys) == 0 {
+		return nil
+	}
+	// The versions are changed, so the values being loaded are not stored
+	_, err := c.redis.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, key := range keys {
+			pipe.Del(ctx, keyPrefix+key)
+			pipe.Incr(ctx, versionPrefix+key)
+			pipe.Expire(ctx, versionPrefix+key, versionTTL)
+		}
+		return nil
+	})
+	return err
+}
+
+// Invalidate removes all the values stored with one of the tags
+func (c *Cache) Invalidate(ctx context.Context, tags ...string) error {
+	if !c.enabled() {
+		return nil
+	}
+	for _, tag := range tags {
+		keys, err := c.redis.SMembers(ctx, tagPrefix+tag).Result()
+		if err != nil {
+			return err
+		}
+		if err := c.Delete(ctx, keys...); err != nil {
+			return err
+		}
+		if err := c.redis.Del(ctx, tagPrefix+tag).Err(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// tagTTL returns how long a tag is kept
+func (c *Cache) tagTTL(ctx context.Context, tag string) time.Duration {
+	ttl, err := c.redis.TTL(ctx, tagPrefix+tag).Result()
+	if err != nil || ttl < 0 {
+		return 0
+	}
+	return ttl
+}
@@ -0,0 +1,51 @@
+package cache
+
+import (
+	"context"
+	"sync"
+)
+
+// call is a load in progress
+type call struct {
+	done  chan struct{}
+	value any
+	err   error
+}
+
+// group merges the concurrent loads of a same key,
+// it prevents a stampede on the database when a frequently read value expires
+type group struct {
+	mutex sync.Mutex
+	calls map[string]*call
+}
+
+// do runs the load once for all the concurrent callers of a key
+// The callers arriving while the load runs wait for it and share its result,
+// unless their context is done first
+func (g *group) do(ctx context.Context, key string, load func() (any, error)) (any, error) {
+	g.mutex.Lock()
+	if g.calls == nil {
+		g.calls = map[string]*call{}
+	}
+	if c, ok := g.calls[key]; ok {
+		g.mutex.Unlock()
+		select {
+		case <-c.done:
+			return c.value, c.err
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	c := &call{done: make(chan struct{})}
+	g.calls[key] = c
+	g.mutex.Unlock()
+
+	defer func() {
+		g.mutex.Lock()
+		delete(g.calls, key)
+		g.mutex.Unlock()
+		close(c.done)
+	}()
+	c.value, c.err = load()
+	return c.value, c.err
+}
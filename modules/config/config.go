@@ -58,6 +58,101 @@ type AppConfig struct {
 		}
 	}
 
+	// Registration holds the registration policy
+	// It defines who can sign up
+	Registration struct {
+		mode string `env:"REGISTRATION_MODE" default:"open" validate:"required,oneof=open invite-only domain-restricted closed"`
+		Mode RegistrationMode
+		// AllowedDomains is the list of email domains allowed to sign up in domain-restricted mode
+		AllowedDomains []string `env:"REGISTRATION_ALLOWED_DOMAINS"`
+		// DeniedDomainsFile is a file listing the email domains which can never sign up,
+		// such as disposable email providers. There is one domain per line.
+		DeniedDomainsFile string `env:"REGISTRATION_DENIED_DOMAINS_FILE" default:"data/disposable-email-domains.txt"`
+	}
+
+	// Invitations holds the configuration of the invitations to join an organization
+	Invitations struct {
+		// MaxAge is the number of hours an invitation can be accepted
+		MaxAge int `env:"INVITATION_MAX_AGE" default:"168" validate:"required"`
+	}
+
+	// Idempotency holds the configuration of the requests sent with an Idempotency-Key header
+	Idempotency struct {
+		// MaxAge is the number of hours the response of a request is replayed when it is retried with the same key
+		MaxAge int `env:"IDEMPOTENCY_KEY_MAX_AGE" default:"24" validate:"required"`
+	}
+
+	// RateLimit holds the limits of the number of requests sent by the clients
+	// The requests are counted in Redis, so the limits are shared by all the instances of the server
+	RateLimit struct {
+		Enable bool `env:"RATE_LIMIT_ENABLE" default:"true"`
+		// Default limits the requests sent by each user, or by each ip address for the anonymous clients
+		Default struct {
+			// Requests is the number of requests allowed during the period
+			Requests int `env:"RATE_LIMIT_REQUESTS" default:"300" validate:"required"`
+			// Period is the number of seconds of the period
+			Period int `env:"RATE_LIMIT_PERIOD" default:"60" validate:"required"`
+		}
+		// Auth limits the requests sent by each ip address to the authentication routes
+		Auth struct {
+			// Requests is the number of requests allowed during the period
+			Requests int `env:"RATE_LIMIT_AUTH_REQUESTS" default:"10" validate:"required"`
+			// Period is the number of seconds of the period
+			Period int `env:"RATE_LIMIT_AUTH_PERIOD" default:"60" validate:"required"`
+		}
+	}
+
+	// Cache holds the configuration of the cache stored in Redis
+	Cache struct {
+		Enable bool `env:"CACHE_ENABLE" default:"true"`
+		// TTL is the number of seconds a value is kept in the cache
+		TTL int `env:"CACHE_TTL" default:"300" validate:"required"`
+	}
+
+	// Health holds the configuration of the liveness and readiness probes
+	Health struct {
+		// Timeout is the number of seconds a check can take before it fails
+		Timeout int `env:"HEALTH_CHECK_TIMEOUT" default:"2" validate:"required"`
+		// CacheTTL is the number of seconds the result of a check is reused by the following probes
+		CacheTTL int `env:"HEALTH_CHECK_CACHE_TTL" default:"5" validate:"gte=0"`
+	}
+
+	// Metrics holds the configuration of the metrics exposed to Prometheus
+	Metrics struct {
+		Enable bool `env:"METRICS_ENABLE" default:"true"`
+		// Port serves the metrics on a separate admin port, which must not be exposed to the clients.
+		// The metrics are never served by the api, they would disclose the routes, the database and Redis to anyone
+		Port string `env:"METRICS_PORT" default:"9090" validate:"required_if=Enable true"`
+	}
+
+	// Tracing holds the configuration of the OpenTelemetry traces
+	Tracing struct {
+		Enable bool `env:"TRACING_ENABLE" default:"false"`
+		// Exporter sends the spans to an OTLP collector over http, or prints them to stdout
+		Exporter string `env:"TRACING_EXPORTER" default:"otlp" validate:"oneof=otlp stdout"`
+		// Endpoint is the host and port of the OTLP collector.
+		// The standard OTEL_EXPORTER_OTLP_* variables are used if it is empty
+		Endpoint string `env:"TRACING_OTLP_ENDPOINT"`
+		// Insecure sends the spans over http instead of https
+		Insecure bool `env:"TRACING_OTLP_INSECURE" default:"false"`
+		// SampleRatio is the ratio of the traces started by the server which are recorded,
+		// the traces started by the clients follow their sampling decision
+		SampleRatio float64 `env:"TRACING_SAMPLE_RATIO" default:"1" validate:"gte=0,lte=1"`
+	}
+
+	// Pagination holds the configuration of the paginated lists
+	Pagination struct {
+		// CursorSecret signs the pagination cursors, so they cannot be forged by the clients.
+		// The access token private key is used if it is empty.
+		CursorSecret string `env:"PAGINATION_CURSOR_SECRET"`
+	}
+
+	// I18n holds the configuration of the translations
+	I18n struct {
+		// DefaultLocale is used when the client accepts none of the supported locales
+		DefaultLocale string `env:"I18N_DEFAULT_LOCALE" default:"en" validate:"required"`
+	}
+
 	// Logs defines how the logs are written
 	// There is two log files : access and database
 	Logs struct {
@@ -92,6 +187,21 @@ type AppConfig struct {
 			// Compress determines if the rotated log files should be compressed
 			// using gzip. The default is not to perform compression.
 			Compress bool `env:"ACCESS_LOG_COMPRESS" default:"false"`
+
+			// Enable writes a structured event for each request in the access log.
+			Enable bool `env:"ACCESS_LOG_ENABLE" default:"true"`
+
+			// SampleRate is the ratio of the successful requests which are logged.
+			// The requests which fail with a 4xx or a 5xx status are always logged.
+			SampleRate float64 `env:"ACCESS_LOG_SAMPLE_RATE" default:"1" validate:"gte=0,lte=1"`
+
+			// Exclude are the paths which are not logged, relative to the base path,
+			// such as the probes. A path also excludes the paths below it.
+			Exclude []string `env:"ACCESS_LOG_EXCLUDE" default:"/livez,/readyz,/healthcheck,/ping,/metrics"`
+
+			// Redact are the query parameters whose values are hidden in the access log,
+			// such as the tokens. They are compared without case.
+			Redact []string `env:"ACCESS_LOG_REDACT" default:"token,key,code,password,secret"`
 		}
 
 		// Configure the database log file, it's size, age, and how many backups to retain.
@@ -135,6 +245,46 @@ type AppConfig struct {
 		Port     string `env:"APP_PORT" default:"8080" validate:"required"`
 		BasePath string `env:"APP_BASE_PATH" default:"/api" validate:"required"`
 		Url      string
+
+		// ShutdownTimeout is the number of seconds given to the in-flight requests and to the queued mails
+		// when the server is stopped, they are dropped after this grace period
+		ShutdownTimeout int `env:"APP_SHUTDOWN_TIMEOUT" default:"30" validate:"required"`
+
+		// Timeouts of the connections in seconds, zero means no timeout
+		// ReadTimeout is the maximum duration for reading an entire request, including the body
+		ReadTimeout int `env:"APP_READ_TIMEOUT" default:"30" validate:"gte=0"`
+		// ReadHeaderTimeout is the maximum duration for reading the headers of a request
+		ReadHeaderTimeout int `env:"APP_READ_HEADER_TIMEOUT" default:"5" validate:"gte=0"`
+		// WriteTimeout is the maximum duration before timing out the writes of a response
+		WriteTimeout int `env:"APP_WRITE_TIMEOUT" default:"60" validate:"gte=0"`
+		// IdleTimeout is the maximum duration to wait for the next request when keep-alives are enabled
+		IdleTimeout int `env:"APP_IDLE_TIMEOUT" default:"120" validate:"gte=0"`
+
+		// MaxHeaderBytes is the maximum size in bytes of the headers of a request
+		MaxHeaderBytes int `env:"APP_MAX_HEADER_BYTES" default:"1048576" validate:"gte=0"`
+		// MaxBodyBytes is the maximum size in bytes of the body of a request, zero means no limit
+		MaxBodyBytes int64 `env:"APP_MAX_BODY_BYTES" default:"1048576" validate:"gte=0"`
+
+		// TrustedProxies are the ip addresses or the CIDR ranges of the proxies in front of the server.
+		// The ip address of the client, used by the rate limits and the logs, is read from the X-Forwarded-For
+		// and X-Real-IP headers only when they are set by one of them. No proxy is trusted if it is empty
+		TrustedProxies []string `env:"APP_TRUSTED_PROXIES" validate:"dive,cidr|ip"`
+
+		// TLS holds the configuration of the native TLS, the server listens on http if it is not enabled.
+		// The certificates are reloaded when the server receives a SIGHUP signal.
+		TLS struct {
+			Enable   bool
+			CertFile string `env:"APP_TLS_CERT_FILE" validate:"required_with=KeyFile"`
+			KeyFile  string `env:"APP_TLS_KEY_FILE" validate:"required_with=CertFile"`
+			// MinVersion is the minimum version of TLS accepted by the server
+			MinVersion string `env:"APP_TLS_MIN_VERSION" default:"1.2" validate:"oneof=1.0 1.1 1.2 1.3"`
+			// CipherSuites are the names of the cipher suites of TLS 1.0 to 1.2, the Go defaults are used if empty
+			CipherSuites []string `env:"APP_TLS_CIPHER_SUITES"`
+			// ClientAuth is the policy of the authentication of the clients by a certificate (mTLS)
+			ClientAuth string `env:"APP_TLS_CLIENT_AUTH" default:"none" validate:"oneof=none request require verify-if-given require-and-verify"`
+			// ClientCAFile holds the certificates of the authorities which sign the certificates of the clients
+			ClientCAFile string `env:"APP_TLS_CLIENT_CA_FILE" validate:"required_if=ClientAuth verify-if-given,required_if=ClientAuth require-and-verify"`
+		}
 	}
 
 	// Client holds the client url
@@ -152,6 +302,15 @@ type AppConfig struct {
 		Name             string `env:"POSTGRES_NAME" validate:"required"`
 		Charset          string `env:"POSTGRES_CHARSET" default:"utf8mb4"`
 		ConnectionString string `env:"POSTGRES_CONNECTION_STRING"`
+
+		// Migrations defines how the pending migrations are handled when the server starts
+		Migrations struct {
+			// Apply applies the pending migrations before starting the server
+			Apply bool `env:"DATABASE_MIGRATIONS_APPLY" default:"false"`
+			// FailOnPending prevents the server from starting when some migrations are pending.
+			// When disabled, a warning is logged instead.
+			FailOnPending bool `env:"DATABASE_MIGRATIONS_FAIL_ON_PENDING" default:"true"`
+		}
 	}
 
 	// Redis holds the redis configuration
@@ -232,6 +391,12 @@ func (c *AppConfig) Setup() {
 	// update log config
 	c.setupLogs()
 
+	// update registration config
+	c.setupRegistration()
+
+	// update pagination config
+	c.setupPagination()
+
 	// update database config
 	c.setupDatabase()
 
@@ -259,8 +424,11 @@ func (c *AppConfig) setupApp() {
 
 // setupServer updates the server config
 func (c *AppConfig) setupServer() {
-	// Set the server scheme to http in debug mode
-	if c.App.Debug {
+	// Enable TLS if a certificate is given
+	c.Server.TLS.Enable = c.Server.TLS.CertFile != ""
+
+	// Set the server scheme to http in debug mode, unless TLS is enabled
+	if c.App.Debug && !c.Server.TLS.Enable {
 		c.Server.Scheme = "http"
 	} else {
 		c.Server.Scheme = "https"
@@ -282,6 +450,35 @@ func (c *AppConfig) setupLogs() {
 	}
 }
 
+// setupRegistration updates the registration config
+func (c *AppConfig) setupRegistration() {
+	// Registration mode
+	c.Registration.Mode = RegistrationMode(c.Registration.mode)
+
+	// Clean the allowed domains
+	domains := make([]string, 0, len(c.Registration.AllowedDomains))
+	for _, domain := range c.Registration.AllowedDomains {
+		domain = strings.ToLower(strings.TrimSpace(domain))
+		if domain != "" {
+			domains = append(domains, domain)
+		}
+	}
+	c.Registration.AllowedDomains = domains
+
+	// A domain restricted registration without any domain is closed
+	if c.Registration.Mode.IsDomainRestricted() && len(c.Registration.AllowedDomains) == 0 {
+		log.Warn().Msg("Registration is domain-restricted but no domain is allowed")
+	}
+}
+
+// setupPagination updates the pagination config
+func (c *AppConfig) setupPagination() {
+	// Sign the cursors with the access token private key by default
+	if len(c.Pagination.CursorSecret) == 0 {
+		c.Pagination.CursorSecret = c.Tokens.Access.PrivateKey
+	}
+}
+
 // setupDatabase updates the database config
 func (c *AppConfig) setupDatabase() {
 	// Create database connection string
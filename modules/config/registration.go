@@ -0,0 +1,42 @@
+package config
+
+// RegistrationMode is an enum for the registration policy
+// It can be "open", "invite-only", "domain-restricted" or "closed"
+type RegistrationMode string
+
+const (
+	// RegistrationOpen lets anyone sign up
+	RegistrationOpen RegistrationMode = "open"
+	// RegistrationInviteOnly only lets invited users sign up
+	RegistrationInviteOnly RegistrationMode = "invite-only"
+	// RegistrationDomainRestricted only lets users with an allowed email domain sign up
+	// Invited users can always sign up
+	RegistrationDomainRestricted RegistrationMode = "domain-restricted"
+	// RegistrationClosed disables the sign up
+	RegistrationClosed RegistrationMode = "closed"
+)
+
+// String returns the string representation of the registration mode
+func (m RegistrationMode) String() string {
+	return string(m)
+}
+
+// IsOpen returns true if anyone can sign up
+func (m RegistrationMode) IsOpen() bool {
+	return m == RegistrationOpen
+}
+
+// IsInviteOnly returns true if only invited users can sign up
+func (m RegistrationMode) IsInviteOnly() bool {
+	return m == RegistrationInviteOnly
+}
+
+// IsDomainRestricted returns true if only the allowed email domains can sign up
+func (m RegistrationMode) IsDomainRestricted() bool {
+	return m == RegistrationDomainRestricted
+}
+
+// IsClosed returns true if nobody can sign up
+func (m RegistrationMode) IsClosed() bool {
+	return m == RegistrationClosed
+}
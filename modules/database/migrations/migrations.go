@@ -0,0 +1,315 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"github.com/rs/zerolog/log"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// files holds the migration files embedded in the binary
+//
+//go:embed sql/*.sql
+var files embed.FS
+
+// Dir is the directory where the migration files are created
+// It is relative to the root of the project
+const Dir = "modules/database/migrations/sql"
+
+// lockId is the key of the postgres advisory lock
+// which prevents two migrators from running at the same time
+const lockId = 7_342_159_004
+
+// fileName matches the name of a migration file: <version>_<name>.<up|down>.sql
+var fileName = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+// nameSeparator matches the characters which are replaced by an underscore in a migration name
+var nameSeparator = regexp.MustCompile(`[^a-z0-9]+`)
+
+// Migration is a versioned change of the database schema
+type Migration struct {
+	Version   int64
+	Name      string
+	Up        string
+	Down      string
+	AppliedAt *time.Time
+}
+
+// IsApplied returns true if the migration has been applied
+func (m Migration) IsApplied() bool {
+	return m.AppliedAt != nil
+}
+
+// Migrator applies and reverts the migrations
+// The applied migrations are tracked in the schema_migrations table
+type Migrator struct {
+	ctx   context.Context
+	sqlDb *sql.DB
+	fs    fs.FS
+}
+
+// NewMigrator creates a migrator using the embedded migration files
+func NewMigrator(ctx context.Context, sqlDb *sql.DB) *Migrator {
+	return &Migrator{ctx: ctx, sqlDb: sqlDb, fs: files}
+}
+
+// Status returns all the migrations sorted by version
+// with the date they were applied, if any
+func (m *Migrator) Status() ([]Migration, error) {
+	if err := m.createTable(); err != nil {
+		return nil, err
+	}
+	return m.migrations(m.ctx)
+}
+
+// Pending returns the migrations which have not been applied yet
+func (m *Migrator) Pending() ([]Migration, error) {
+	migrations, err := m.Status()
+	if err != nil {
+		return nil, err
+	}
+	return pending(migrations), nil
+}
+
+// pending keeps the migrations which have not been applied yet
+func pending(migrations []Migration) []Migration {
+	var pending []Migration
+	for _, migration := range migrations {
+		if !migration.IsApplied() {
+			pending = append(pending, migration)
+		}
+	}
+	return pending
+}
+
+// PendingContext returns the migrations which have not been applied yet, without changing the database
+// Unlike Pending, it does not create the schema_migrations table, so it can be called by the health checks
+func (m *Migrator) PendingContext(ctx context.Context) ([]Migration, error) {
+	migrations, err := m.migrations(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return pending(migrations), nil
+}
+
+// Up applies the n next pending migrations
+// All pending migrations are applied if n is lower or equal to 0
+func (m *Migrator) Up(n int) ([]Migration, error) {
+	var applied []Migration
+
+	err := m.withLock(func() error {
+		pending, err := m.Pending()
+		if err != nil {
+			return err
+		}
+		if n > 0 && n < len(pending) {
+			pending = pending[:n]
+		}
+
+		for _, migration := range pending {
+			log.Info().Int64("version", migration.Version).Str("name", migration.Name).Msg("Applying migration")
+			err := m.exec(migration.Up, func(tx *sql.Tx) error {
+				_, err := tx.ExecContext(m.ctx, "INSERT INTO schema_migrations (version, name, applied_at) VALUES ($1, $2, $3)",
+					migration.Version, migration.Name, time.Now().UTC())
+				return err
+			})
+			if err != nil {
+				return fmt.Errorf("migration %d_%s: %w", migration.Version, migration.Name, err)
+			}
+			applied = append(applied, migration)
+		}
+		return nil
+	})
+
+	return applied, err
+}
+
+// Down reverts the n last applied migrations
+// Only the last migration is reverted if n is lower or equal to 0
+func (m *Migrator) Down(n int) ([]Migration, error) {
+	var reverted []Migration
+	if n <= 0 {
+		n = 1
+	}
+
+	err := m.withLock(func() error {
+		migrations, err := m.Status()
+		if err != nil {
+			return err
+		}
+
+		for i := len(migrations) - 1; i >= 0 && len(reverted) < n; i-- {
+			migration := migrations[i]
+			if !migration.IsApplied() {
+				continue
+			}
+			if strings.TrimSpace(migration.Down) == "" {
+				return fmt.Errorf("migration %d_%s cannot be reverted", migration.Version, migration.Name)
+			}
+
+			log.Info().Int64("version", migration.Version).Str("name", migration.Name).Msg("Reverting migration")
+			err := m.exec(migration.Down, func(tx *sql.Tx) error {
+				_, err := tx.ExecContext(m.ctx, "DELETE FROM schema_migrations WHERE version = $1", migration.Version)
+				return err
+			})
+			if err != nil {
+				return fmt.Errorf("migration %d_%s: %w", migration.Version, migration.Name, err)
+			}
+			reverted = append(reverted, migration)
+		}
+		return nil
+	})
+
+	return reverted, err
+}
+
+// Create writes a new pair of empty up and down migration files in the directory
+// The version is the current UTC time, which keeps the migrations ordered
+func Create(dir string, name string) (up string, down string, err error) {
+	name = strings.Trim(nameSeparator.ReplaceAllString(strings.ToLower(name), "_"), "_")
+	if name == "" {
+		return "", "", errors.New("the migration name cannot be empty")
+	}
+
+	version := time.Now().UTC().Format("20060102150405")
+	up = filepath.Join(dir, fmt.Sprintf("%s_%s.up.sql", version, name))
+	down = filepath.Join(dir, fmt.Sprintf("%s_%s.down.sql", version, name))
+
+	if err = os.MkdirAll(dir, 0o750); err != nil {
+		return "", "", err
+	}
+	if err = os.WriteFile(up, []byte("-- Write the migration here\n"), 0o600); err != nil {
+		return "", "", err
+	}
+	if err = os.WriteFile(down, []byte("-- Write the migration rollback here\n"), 0o600); err != nil {
+		return "", "", err
+	}
+
+	return up, down, nil
+}
+
+// createTable creates the table which tracks the applied migrations
+func (m *Migrator) createTable() error {
+	_, err := m.sqlDb.ExecContext(m.ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version    bigint PRIMARY KEY,
+		name       text NOT NULL,
+		applied_at timestamptz NOT NULL
+	)`)
+	return err
+}
+
+// migrations reads the migration files and the applied versions
+func (m *Migrator) migrations(ctx context.Context) ([]Migration, error) {
+	// Read the migration files
+	entries, err := fs.ReadDir(m.fs, "sql")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := map[int64]*Migration{}
+	for _, entry := range entries {
+		parts := fileName.FindStringSubmatch(entry.Name())
+		if parts == nil {
+			return nil, fmt.Errorf("invalid migration file name %s", entry.Name())
+		}
+		version, _ := strconv.ParseInt(parts[1], 10, 64)
+		content, err := fs.ReadFile(m.fs, "sql/"+entry.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: parts[2]}
+			byVersion[version] = migration
+		}
+		if migration.Name != parts[2] {
+			return nil, fmt.Errorf("migration version %d is used by %s and %s", version, migration.Name, parts[2])
+		}
+		if parts[3] == "up" {
+			migration.Up = string(content)
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	// Read the applied versions
+	rows, err := m.sqlDb.QueryContext(ctx, "SELECT version, applied_at FROM schema_migrations")
+	if err != nil {
+		return nil, err
+	}
+	defer func(rows *sql.Rows) { _ = rows.Close() }(rows)
+	for rows.Next() {
+		var version int64
+		var appliedAt time.Time
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, err
+		}
+		migration, ok := byVersion[version]
+		if !ok {
+			return nil, fmt.Errorf("applied migration %d is unknown to this binary", version)
+		}
+		migration.AppliedAt = &appliedAt
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Sort the migrations by version
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if strings.TrimSpace(migration.Up) == "" {
+			return nil, fmt.Errorf("migration %d_%s has no up file", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+
+	return migrations, nil
+}
+
+// exec runs a migration script and records it in a single transaction
+func (m *Migrator) exec(script string, record func(tx *sql.Tx) error) error {
+	tx, err := m.sqlDb.BeginTx(m.ctx, nil)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(m.ctx, script); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	if err := record(tx); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// withLock runs the function while holding the migration advisory lock
+func (m *Migrator) withLock(fn func() error) error {
+	// The advisory lock is bound to a connection
+	conn, err := m.sqlDb.Conn(m.ctx)
+	if err != nil {
+		return err
+	}
+	defer func(conn *sql.Conn) { _ = conn.Close() }(conn)
+
+	if _, err := conn.ExecContext(m.ctx, "SELECT pg_advisory_lock($1)", lockId); err != nil {
+		return err
+	}
+	defer func(conn *sql.Conn) {
+		_, _ = conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", lockId)
+	}(conn)
+
+	return fn()
+}
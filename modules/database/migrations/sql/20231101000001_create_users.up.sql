@@ -0,0 +1,22 @@
+CREATE EXTENSION IF NOT EXISTS "uuid-ossp";
+
+CREATE TABLE IF NOT EXISTS users
+(
+    id                 uuid         DEFAULT uuid_generate_v4() PRIMARY KEY,
+    email              text         NOT NULL,
+    name               text         NOT NULL,
+    first_name         text         NOT NULL,
+    last_name          text         NOT NULL,
+    password           text         NOT NULL,
+    role               varchar(255) NOT NULL,
+    verified           boolean      NOT NULL,
+    verification_token text,
+    reset_token        text,
+    last_reset_at      timestamptz,
+    created_at         timestamptz  NOT NULL,
+    updated_at         timestamptz  NOT NULL,
+    deleted_at         timestamptz
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_email ON users (email);
+CREATE INDEX IF NOT EXISTS idx_users_deleted_at ON users (deleted_at);
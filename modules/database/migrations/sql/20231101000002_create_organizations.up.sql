@@ -0,0 +1,27 @@
+CREATE TABLE IF NOT EXISTS organizations
+(
+    id         uuid DEFAULT uuid_generate_v4() PRIMARY KEY,
+    name       text        NOT NULL,
+    slug       text        NOT NULL,
+    created_at timestamptz NOT NULL,
+    updated_at timestamptz NOT NULL,
+    deleted_at timestamptz
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_organizations_slug ON organizations (slug);
+CREATE INDEX IF NOT EXISTS idx_organizations_deleted_at ON organizations (deleted_at);
+
+CREATE TABLE IF NOT EXISTS memberships
+(
+    id              uuid DEFAULT uuid_generate_v4() PRIMARY KEY,
+    user_id         uuid         NOT NULL,
+    organization_id uuid         NOT NULL,
+    role            varchar(255) NOT NULL,
+    created_at      timestamptz  NOT NULL,
+    updated_at      timestamptz  NOT NULL,
+    CONSTRAINT fk_users_memberships FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE,
+    CONSTRAINT fk_organizations_memberships FOREIGN KEY (organization_id) REFERENCES organizations (id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_membership_user_organization ON memberships (user_id, organization_id);
+CREATE INDEX IF NOT EXISTS idx_memberships_organization_id ON memberships (organization_id);
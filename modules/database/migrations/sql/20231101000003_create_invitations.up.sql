@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS invitations
+(
+    id              uuid DEFAULT uuid_generate_v4() PRIMARY KEY,
+    organization_id uuid         NOT NULL,
+    email           text         NOT NULL,
+    role            varchar(255) NOT NULL,
+    token           text         NOT NULL,
+    invited_by_id   uuid         NOT NULL,
+    expires_at      timestamptz  NOT NULL,
+    accepted_at     timestamptz,
+    created_at      timestamptz  NOT NULL,
+    updated_at      timestamptz  NOT NULL,
+    CONSTRAINT fk_invitations_organization FOREIGN KEY (organization_id) REFERENCES organizations (id) ON DELETE CASCADE,
+    CONSTRAINT fk_invitations_invited_by FOREIGN KEY (invited_by_id) REFERENCES users (id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_invitations_organization_id ON invitations (organization_id);
+CREATE INDEX IF NOT EXISTS idx_invitations_email ON invitations (email);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_invitations_token ON invitations (token);
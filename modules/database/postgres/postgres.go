@@ -5,8 +5,12 @@ import (
 	"database/sql"
 	"github.com/go-api-template/go-backend/models"
 	"github.com/go-api-template/go-backend/modules/config"
+	"github.com/go-api-template/go-backend/modules/health"
 	database_logger "github.com/go-api-template/go-backend/modules/logger/database"
+	"github.com/go-api-template/go-backend/modules/metrics"
+	"github.com/go-api-template/go-backend/modules/tracing"
 	zerologGorm "github.com/go-mods/zerolog-gorm"
+	"github.com/prometheus/client_golang/prometheus/collectors"
 	"github.com/rs/zerolog/log"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
@@ -49,8 +53,21 @@ func (p *postgresDb) initialize(ctx context.Context) {
 		log.Fatal().Err(err).Msg("Initializing Postgres")
 	}
 
+	// Time and trace the queries
+	if err := gormDb.Use(metrics.GormPlugin{}); err != nil {
+		log.Fatal().Err(err).Msg("Adding the metrics to gorm")
+	}
+	if err := gormDb.Use(tracing.GormPlugin{}); err != nil {
+		log.Fatal().Err(err).Msg("Adding the tracing to gorm")
+	}
+
+	// Restrict the queries to the organization of the request
+	if err := gormDb.Use(models.TenantPlugin{}); err != nil {
+		log.Fatal().Err(err).Msg("Adding the tenant scope to gorm")
+	}
+
 	// Add logger to gorm
-	gormDb = gormDb.WithContext(database_logger.DatabaseLogger.WithContext(ctx))
+	gormDb = gormDb.WithContext(database_logger.WithContext(ctx))
 
 	// Get sqlDb from gorm
 	sqlDb, err := gormDb.DB()
@@ -69,6 +86,12 @@ func (p *postgresDb) initialize(ctx context.Context) {
 		gormDb = gormDb.Debug()
 	}
 
+	// Check the connection in the readiness probe
+	health.Register(health.Check{Name: "postgres", Checker: sqlDb.PingContext})
+
+	// Expose the statistics of the pool of connections
+	metrics.Registry.MustRegister(collectors.NewDBStatsCollector(sqlDb, "postgres"))
+
 	// Set the singleton instance
 	p.gormDb = gormDb
 	p.sqlDb = sqlDb
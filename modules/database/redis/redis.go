@@ -4,6 +4,9 @@ import (
 	"context"
 	"fmt"
 	"github.com/go-api-template/go-backend/modules/config"
+	"github.com/go-api-template/go-backend/modules/health"
+	"github.com/go-api-template/go-backend/modules/metrics"
+	"github.com/go-api-template/go-backend/modules/tracing"
 	"github.com/go-redis/redis/v8"
 	"github.com/rs/zerolog/log"
 	"sync"
@@ -36,12 +39,25 @@ func (r *redisDb) initialize(ctx context.Context) {
 		Addr: fmt.Sprintf("%s:%s", config.Config.Redis.Host, config.Config.Redis.Port),
 	})
 
+	// Count, time and trace the commands, and expose the statistics of the pool of connections
+	rc.AddHook(metrics.RedisHook{})
+	rc.AddHook(tracing.RedisHook{})
+	metrics.Registry.MustRegister(metrics.NewRedisPoolCollector(rc))
+
 	// Validate connection to redisDb by pinging it
 	err := rc.Ping(ctx).Err()
 	if err != nil {
 		log.Fatal().Err(err).Msg("Pinging Redis instance")
 	}
 
+	// Check the connection in the readiness probe
+	health.Register(health.Check{
+		Name: "redis",
+		Checker: func(ctx context.Context) error {
+			return rc.Ping(ctx).Err()
+		},
+	})
+
 	// Set redisDb
 	r.redis = rc
 }
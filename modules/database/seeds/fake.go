@@ -0,0 +1,102 @@
+package seeds
+
+import (
+	"fmt"
+	"github.com/go-api-template/go-backend/models"
+	"github.com/go-api-template/go-backend/modules/config"
+	"github.com/go-api-template/go-backend/modules/utils"
+	"github.com/go-faker/faker/v4"
+	"gorm.io/gorm"
+)
+
+// Volumes of the generated data
+const (
+	fakeUsers                  = 50
+	fakeOrganizations          = 10
+	fakeMembersPerOrganization = 5
+)
+
+// FakePassword is the password of all the generated users
+const FakePassword = "password"
+
+// fakeUserEmail returns the email address of the nth generated user
+// The addresses are predictable, so running the seeder twice does not duplicate the users
+func fakeUserEmail(n int) string {
+	return fmt.Sprintf("user%02d@example.com", n)
+}
+
+// usersSeeder generates users with random names
+var usersSeeder = Seeder{
+	Name:           "users",
+	Description:    fmt.Sprintf("%d verified users, from %s to %s", fakeUsers, fakeUserEmail(1), fakeUserEmail(fakeUsers)),
+	Environnements: []config.Environnement{config.Development},
+	Seed: func(tx *gorm.DB, _ config.Environnement) error {
+		// Hash the password only once, hashing is slow on purpose
+		password, err := utils.HashPassword(FakePassword)
+		if err != nil {
+			return err
+		}
+
+		for n := 1; n <= fakeUsers; n++ {
+			_, err := seedUser(tx, &models.User{
+				Email:     fakeUserEmail(n),
+				Password:  password,
+				FirstName: faker.FirstName(),
+				LastName:  faker.LastName(),
+				Role:      models.RoleUser,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
+
+// organizationsSeeder generates organizations with random names
+// and gives them members among the generated users
+var organizationsSeeder = Seeder{
+	Name:           "organizations",
+	Description:    fmt.Sprintf("%d organizations with %d members each", fakeOrganizations, fakeMembersPerOrganization),
+	Environnements: []config.Environnement{config.Development},
+	Seed: func(tx *gorm.DB, _ config.Environnement) error {
+		// Get the generated users
+		emails := make([]string, fakeUsers)
+		for n := 1; n <= fakeUsers; n++ {
+			emails[n-1] = fakeUserEmail(n)
+		}
+		var users []models.User
+		if err := tx.Where("email IN ?", emails).Order("email").Find(&users).Error; err != nil {
+			return err
+		}
+		if len(users) == 0 {
+			return fmt.Errorf("no generated user, run the %s seeder first", usersSeeder.Name)
+		}
+
+		for n := 1; n <= fakeOrganizations; n++ {
+			organization, err := seedOrganization(tx, &models.Organization{
+				Name: fmt.Sprintf("%s & %s", faker.LastName(), faker.LastName()),
+				Slug: fmt.Sprintf("organization-%02d", n),
+			})
+			if err != nil {
+				return err
+			}
+
+			// The members are always the same users, the first one is the owner
+			for m := 0; m < fakeMembersPerOrganization; m++ {
+				role := models.OrganizationRoleMember
+				switch m {
+				case 0:
+					role = models.OrganizationRoleOwner
+				case 1:
+					role = models.OrganizationRoleAdmin
+				}
+				user := users[(n*fakeMembersPerOrganization+m)%len(users)]
+				if err := seedMembership(tx, &user, organization, role); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	},
+}
@@ -0,0 +1,182 @@
+package seeds
+
+import (
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/go-api-template/go-backend/models"
+	"github.com/go-api-template/go-backend/modules/config"
+	"github.com/go-api-template/go-backend/modules/utils"
+	"gopkg.in/yaml.v3"
+	"gorm.io/gorm"
+	"io/fs"
+	"path"
+	"strings"
+)
+
+// fixtureFiles holds the fixtures embedded in the binary
+// There is one directory per environnement
+//
+//go:embed fixtures
+var fixtureFiles embed.FS
+
+// fixtures is the content of a fixture file
+// A fixture file can be written in YAML or in JSON
+type fixtures struct {
+	Users         []userFixture         `yaml:"users"         json:"users"`
+	Organizations []organizationFixture `yaml:"organizations" json:"organizations"`
+}
+
+// userFixture describes a user with a verified email address
+type userFixture struct {
+	Email     string      `yaml:"email"      json:"email"`
+	Password  string      `yaml:"password"   json:"password"`
+	FirstName string      `yaml:"first_name" json:"first_name"`
+	LastName  string      `yaml:"last_name"  json:"last_name"`
+	Role      models.Role `yaml:"role"       json:"role"`
+}
+
+// organizationFixture describes an organization and its members
+type organizationFixture struct {
+	Name    string `yaml:"name" json:"name"`
+	Slug    string `yaml:"slug" json:"slug"`
+	Members []struct {
+		Email string                  `yaml:"email" json:"email"`
+		Role  models.OrganizationRole `yaml:"role"  json:"role"`
+	} `yaml:"members" json:"members"`
+}
+
+// fixturesSeeder loads the fixture files of the environnement
+var fixturesSeeder = Seeder{
+	Name:           "fixtures",
+	Description:    "Users and organizations described in the fixture files",
+	Environnements: []config.Environnement{config.Development, config.Staging},
+	Seed: func(tx *gorm.DB, env config.Environnement) error {
+		dir := path.Join("fixtures", env.String())
+		entries, err := fs.ReadDir(fixtureFiles, dir)
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		for _, entry := range entries {
+			if entry.IsDir() {
+				continue
+			}
+			f, err := readFixtures(path.Join(dir, entry.Name()))
+			if err != nil {
+				return err
+			}
+			if err := f.seed(tx); err != nil {
+				return fmt.Errorf("%s: %w", entry.Name(), err)
+			}
+		}
+		return nil
+	},
+}
+
+// readFixtures decodes a fixture file according to its extension
+func readFixtures(name string) (*fixtures, error) {
+	content, err := fs.ReadFile(fixtureFiles, name)
+	if err != nil {
+		return nil, err
+	}
+
+	f := &fixtures{}
+	switch strings.ToLower(path.Ext(name)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(content, f)
+	case ".json":
+		err = json.Unmarshal(content, f)
+	default:
+		return nil, fmt.Errorf("unsupported fixture file %s", name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+
+	return f, nil
+}
+
+// seed inserts the users first, then the organizations which reference them
+func (f *fixtures) seed(tx *gorm.DB) error {
+	for _, fixture := range f.Users {
+		if fixture.Role == "" {
+			fixture.Role = models.RoleUser
+		}
+		if _, err := models.ParseRole(string(fixture.Role)); err != nil {
+			return fmt.Errorf("user %s: %w", fixture.Email, err)
+		}
+		password, err := utils.HashPassword(fixture.Password)
+		if err != nil {
+			return err
+		}
+		_, err = seedUser(tx, &models.User{
+			Email:     fixture.Email,
+			Password:  password,
+			FirstName: fixture.FirstName,
+			LastName:  fixture.LastName,
+			Role:      fixture.Role,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, fixture := range f.Organizations {
+		organization, err := seedOrganization(tx, &models.Organization{Name: fixture.Name, Slug: fixture.Slug})
+		if err != nil {
+			return err
+		}
+		for _, member := range fixture.Members {
+			user := &models.User{}
+			if err := tx.Where("email = ?", strings.ToLower(member.Email)).First(user).Error; err != nil {
+				return fmt.Errorf("member %s of %s: %w", member.Email, fixture.Name, err)
+			}
+			if err := seedMembership(tx, user, organization, member.Role); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// seedUser creates a verified user unless a user with the same email address exists
+func seedUser(tx *gorm.DB, user *models.User) (*models.User, error) {
+	user.Email = strings.ToLower(user.Email)
+	user.Name = strings.TrimSpace(user.FirstName + " " + user.LastName)
+	user.Verified = true
+
+	result := tx.Unscoped().Where(models.User{Email: user.Email}).FirstOrCreate(user)
+	return user, result.Error
+}
+
+// seedOrganization creates an organization unless an organization with the same slug exists
+func seedOrganization(tx *gorm.DB, organization *models.Organization) (*models.Organization, error) {
+	if organization.Slug == "" {
+		organization.Slug = utils.Slugify(organization.Name)
+	}
+
+	result := tx.Unscoped().Where(models.Organization{Slug: organization.Slug}).FirstOrCreate(organization)
+	return organization, result.Error
+}
+
+// seedMembership adds the user to the organization unless the user is already a member
+func seedMembership(tx *gorm.DB, user *models.User, organization *models.Organization, role models.OrganizationRole) error {
+	if role == "" {
+		role = models.OrganizationRoleMember
+	}
+	if _, err := models.ParseOrganizationRole(string(role)); err != nil {
+		return fmt.Errorf("member %s of %s: %w", user.Email, organization.Slug, err)
+	}
+
+	membership := &models.Membership{}
+	return tx.
+		Where(models.Membership{UserID: user.ID, OrganizationID: organization.ID}).
+		Attrs(models.Membership{Role: role}).
+		FirstOrCreate(membership).Error
+}
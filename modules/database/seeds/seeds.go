@@ -0,0 +1,89 @@
+package seeds
+
+import (
+	"context"
+	"fmt"
+	"github.com/go-api-template/go-backend/modules/config"
+	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
+	"slices"
+	"strings"
+)
+
+// Seeder fills the database with data
+// A seeder must be idempotent: running it twice must not duplicate the data
+type Seeder struct {
+	// Name is used to select the seeder from the command line
+	Name string
+	// Description explains which data is seeded
+	Description string
+	// Environnements are the environnements in which the seeder runs
+	Environnements []config.Environnement
+	// Seed inserts the data using the given transaction
+	Seed func(tx *gorm.DB, env config.Environnement) error
+}
+
+// RunsIn returns true if the seeder is registered for the environnement
+func (s Seeder) RunsIn(env config.Environnement) bool {
+	return slices.Contains(s.Environnements, env)
+}
+
+// seeders is the list of the seeders, in the order they are run
+// The organizations depend on the users, so the users are seeded first
+var seeders = []Seeder{
+	fixturesSeeder,
+	usersSeeder,
+	organizationsSeeder,
+}
+
+// Seeders returns the seeders registered for the environnement
+func Seeders(env config.Environnement) []Seeder {
+	var registered []Seeder
+	for _, seeder := range seeders {
+		if seeder.RunsIn(env) {
+			registered = append(registered, seeder)
+		}
+	}
+	return registered
+}
+
+// Run runs the seeders registered for the environnement
+// If only is not empty, only the seeders with these names are run
+// Each seeder runs in its own transaction
+func Run(ctx context.Context, gormDb *gorm.DB, env config.Environnement, only []string) ([]Seeder, error) {
+	registered := Seeders(env)
+
+	// Select the seeders to run
+	selected := registered
+	if len(only) > 0 {
+		selected = nil
+		for _, seeder := range registered {
+			if slices.Contains(only, seeder.Name) {
+				selected = append(selected, seeder)
+			}
+		}
+		if len(selected) != len(only) {
+			names := make([]string, len(registered))
+			for i, seeder := range registered {
+				names[i] = seeder.Name
+			}
+			return nil, fmt.Errorf("unknown seeder in %s, the %s seeders are: %s",
+				strings.Join(only, ", "), env, strings.Join(names, ", "))
+		}
+	}
+
+	// Run the seeders
+	var ran []Seeder
+	for _, seeder := range selected {
+		log.Info().Str("seeder", seeder.Name).Str("env", env.String()).Msg("Seeding")
+		err := gormDb.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			return seeder.Seed(tx, env)
+		})
+		if err != nil {
+			return ran, fmt.Errorf("seeder %s: %w", seeder.Name, err)
+		}
+		ran = append(ran, seeder)
+	}
+
+	return ran, nil
+}
@@ -0,0 +1,149 @@
+package health
+
+import (
+	"context"
+	"github.com/go-api-template/go-backend/modules/config"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Checker checks that a component works, it returns an error if it does not
+// It must stop when the context is done
+type Checker func(ctx context.Context) error
+
+// Check is a check of a component registered by the component itself
+type Check struct {
+	// Name identifies the check, it is shown in the probes and can be excluded from them
+	Name string
+	// Checker checks the component
+	Checker Checker
+	// Timeout of the check, the default timeout of the configuration is used if it is zero
+	Timeout time.Duration
+	// Liveness adds the check to the liveness probe, which restarts the server when it fails.
+	// All the checks are part of the readiness probe, which stops sending requests to the server
+	Liveness bool
+}
+
+// Result is the result of a check
+type Result struct {
+	Name      string
+	Err       error
+	CheckedAt time.Time
+}
+
+var (
+	// checks are the registered checks by name
+	checks = map[string]Check{}
+	// results are the last results of the checks by name
+	results = map[string]Result{}
+	// mutex protects the checks and their results
+	mutex sync.Mutex
+)
+
+func init() {
+	// The ping check always succeeds, it tells that the server handles the requests
+	Register(Check{
+		Name:     "ping",
+		Checker:  func(context.Context) error { return nil },
+		Liveness: true,
+	})
+}
+
+// Register registers the check of a component
+// A check registered with the same name replaces the previous one
+func Register(check Check) {
+	mutex.Lock()
+	defer mutex.Unlock()
+	checks[check.Name] = check
+	delete(results, check.Name)
+}
+
+// Names returns the sorted names of the checks of a probe
+func Names(liveness bool) []string {
+	mutex.Lock()
+	defer mutex.Unlock()
+	var names []string
+	for name, check := range checks {
+		if check.Liveness || !liveness {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Run runs the checks of a probe, except the excluded ones, and returns their results sorted by name
+// The liveness probe only runs the liveness checks, the readiness probe runs all of them.
+// The checks run concurrently and their results are cached for a few seconds,
+// so the probes do not overload the components
+func Run(ctx context.Context, liveness bool, exclude []string) []Result {
+	names := Names(liveness)
+	names = slices.DeleteFunc(names, func(name string) bool {
+		return slices.Contains(exclude, name)
+	})
+
+	list := make([]Result, len(names))
+	var wg sync.WaitGroup
+	for i, name := range names {
+		wg.Add(1)
+		go func(i int, name string) {
+			defer wg.Done()
+			list[i], _ = RunOne(ctx, name)
+		}(i, name)
+	}
+	wg.Wait()
+	return list
+}
+
+// RunOne runs a check, or returns its cached result
+// It returns false if there is no check with this name
+func RunOne(ctx context.Context, name string) (Result, bool) {
+	mutex.Lock()
+	check, ok := checks[name]
+	result, cached := results[name]
+	mutex.Unlock()
+
+	if !ok {
+		return Result{Name: name}, false
+	}
+	if cached && time.Since(result.CheckedAt) < cacheTTL() {
+		return result, true
+	}
+
+	timeout := check.Timeout
+	if timeout <= 0 {
+		timeout = time.Duration(config.Config.Health.Timeout) * time.Second
+	}
+	// The check is not canceled with the probe, otherwise a probe aborted by the client
+	// would cache a failure which says nothing about the component
+	checkCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), timeout)
+	defer cancel()
+	result = Result{Name: name, Err: run(checkCtx, check.Checker), CheckedAt: time.Now()}
+
+	mutex.Lock()
+	results[name] = result
+	mutex.Unlock()
+	return result, true
+}
+
+// run runs a checker until the context is done
+// A checker which ignores the context is abandoned once the timeout is reached
+func run(ctx context.Context, checker Checker) error {
+	done := make(chan error, 1)
+	go func() {
+		done <- checker(ctx)
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// cacheTTL returns how long the results of the checks are cached
+func cacheTTL() time.Duration {
+	return time.Duration(config.Config.Health.CacheTTL) * time.Second
+}
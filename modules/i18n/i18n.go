@@ -0,0 +1,184 @@
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"github.com/BurntSushi/toml"
+	"github.com/gin-gonic/gin"
+	"github.com/go-api-template/go-backend/modules/config"
+	"github.com/rs/zerolog/log"
+	"golang.org/x/text/language"
+	"io/fs"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// LocaleKey is the key of the locale of the request in the gin context
+const LocaleKey = "locale"
+
+// SourceLocale is the locale of the messages written in the code, it does not need a catalog
+const SourceLocale = "en"
+
+// Catalog maps a message, written in the source locale, to its translation
+// The parameters of a message are written between braces, such as {field}
+type Catalog map[string]string
+
+// Params are the values of the parameters of a message
+type Params map[string]any
+
+// Dir is the directory of the catalogs, there is one directory per locale
+// A catalog can be written in JSON or TOML, all the files of a locale are merged
+const Dir = "locales"
+
+//go:embed locales
+var files embed.FS
+
+var (
+	// catalogs are the catalogs by locale
+	catalogs map[string]Catalog
+	// locales are the supported locales, the default locale first
+	locales []string
+	// matcher finds the best supported locale for the Accept-Language header
+	matcher language.Matcher
+	// Prevent multiple loading
+	once sync.Once
+)
+
+// load loads the embedded catalogs
+func load() {
+	once.Do(func() {
+		catalogs = map[string]Catalog{}
+
+		entries, err := fs.ReadDir(files, Dir)
+		if err != nil {
+			log.Fatal().Err(err).Msg("Reading the translations")
+		}
+		for _, entry := range entries {
+			if !entry.IsDir() {
+				continue
+			}
+			catalog, err := loadCatalog(path.Join(Dir, entry.Name()))
+			if err != nil {
+				log.Fatal().Err(err).Str("locale", entry.Name()).Msg("Loading the translations")
+			}
+			catalogs[entry.Name()] = catalog
+		}
+
+		// The default locale is the first one, it is chosen when none matches
+		defaultLocale := DefaultLocale()
+		if _, ok := catalogs[defaultLocale]; !ok && defaultLocale != SourceLocale {
+			log.Warn().Str("locale", defaultLocale).Msg("The default locale has no translations")
+		}
+		locales = []string{defaultLocale}
+		for locale := range catalogs {
+			if locale != defaultLocale {
+				locales = append(locales, locale)
+			}
+		}
+		if !slices.Contains(locales, SourceLocale) {
+			locales = append(locales, SourceLocale)
+		}
+		sort.Strings(locales[1:])
+
+		tags := make([]language.Tag, len(locales))
+		for i, locale := range locales {
+			tags[i] = language.Make(locale)
+		}
+		matcher = language.NewMatcher(tags)
+	})
+}
+
+// loadCatalog merges the catalogs of a locale directory
+func loadCatalog(dir string) (Catalog, error) {
+	catalog := Catalog{}
+	entries, err := fs.ReadDir(files, dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		name := path.Join(dir, entry.Name())
+		data, err := files.ReadFile(name)
+		if err != nil {
+			return nil, err
+		}
+		messages := Catalog{}
+		switch path.Ext(name) {
+		case ".json":
+			err = json.Unmarshal(data, &messages)
+		case ".toml":
+			err = toml.Unmarshal(data, &messages)
+		default:
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", name, err)
+		}
+		for message, translation := range messages {
+			catalog[message] = translation
+		}
+	}
+	return catalog, nil
+}
+
+// DefaultLocale returns the locale used when no supported locale is requested
+func DefaultLocale() string {
+	return config.Config.I18n.DefaultLocale
+}
+
+// Locales returns the supported locales, the default locale first
+func Locales() []string {
+	load()
+	return slices.Clone(locales)
+}
+
+// Supported returns true if the locale is supported
+func Supported(locale string) bool {
+	load()
+	return slices.Contains(locales, locale)
+}
+
+// Negotiate returns the supported locale which best matches the Accept-Language header
+// The default locale is returned when none matches
+func Negotiate(acceptLanguage string) string {
+	load()
+	tags, _, err := language.ParseAcceptLanguage(acceptLanguage)
+	if err != nil || len(tags) == 0 {
+		return locales[0]
+	}
+	_, index, confidence := matcher.Match(tags...)
+	if confidence == language.No {
+		return locales[0]
+	}
+	return locales[index]
+}
+
+// Locale returns the locale of the request
+// It is the locale of the connected user, if any, otherwise the one negotiated with the Accept-Language header
+func Locale(ctx *gin.Context) string {
+	if locale := ctx.GetString(LocaleKey); locale != "" {
+		return locale
+	}
+	return Negotiate(ctx.GetHeader("Accept-Language"))
+}
+
+// Translate returns the message in the locale, with its parameters replaced by their values
+// The message is returned in the source locale if it has no translation
+func Translate(locale string, message string, params Params) string {
+	load()
+	if translation, ok := catalogs[locale][message]; ok && translation != "" {
+		message = translation
+	}
+	if len(params) == 0 {
+		return message
+	}
+
+	replacements := make([]string, 0, len(params)*2)
+	for name, value := range params {
+		replacements = append(replacements, "{"+name+"}", fmt.Sprint(value))
+	}
+	return strings.NewReplacer(replacements...).Replace(message)
+}
@@ -2,6 +2,7 @@ package access_logger
 
 import (
 	"github.com/go-api-template/go-backend/modules/config"
+	"github.com/go-api-template/go-backend/modules/tracing"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 	"gopkg.in/natefinch/lumberjack.v2"
@@ -33,6 +34,7 @@ func init() {
 		AccessLogger = zerolog.New(fileWriter).
 			Level(zerolog.TraceLevel).
 			With().Timestamp().
-			Logger()
+			Logger().
+			Hook(tracing.LogHook{})
 	}
 }
@@ -1,6 +1,7 @@
 package console_logger
 
 import (
+	"github.com/go-api-template/go-backend/modules/tracing"
 	"github.com/go-mods/zerolog-quick/console/colored"
 	"github.com/mattn/go-isatty"
 	"github.com/rs/zerolog"
@@ -33,7 +34,8 @@ func NewConsoleLogger() zerolog.Logger {
 		Level(zerolog.InfoLevel).
 		With().
 		Timestamp().
-		Logger()
+		Logger().
+		Hook(tracing.LogHook{})
 
 	return l
 }
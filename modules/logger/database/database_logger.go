@@ -1,7 +1,10 @@
 package database_logger
 
 import (
+	"context"
 	"github.com/go-api-template/go-backend/modules/config"
+	request_logger "github.com/go-api-template/go-backend/modules/logger/request"
+	"github.com/go-api-template/go-backend/modules/tracing"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 	"gopkg.in/natefinch/lumberjack.v2"
@@ -33,6 +36,17 @@ func init() {
 		DatabaseLogger = zerolog.New(fileWriter).
 			Level(zerolog.TraceLevel).
 			With().Timestamp().
-			Logger()
+			Logger().
+			Hook(tracing.LogHook{})
 	}
 }
+
+// WithContext returns a copy of the context holding the database logger
+// The logger is bound to the context, so its events carry the id and the trace of the request of the query
+func WithContext(ctx context.Context) context.Context {
+	logger := DatabaseLogger.With().Ctx(ctx)
+	if requestId := request_logger.RequestId(ctx); requestId != "" {
+		logger = logger.Str("request_id", requestId)
+	}
+	return logger.Logger().WithContext(ctx)
+}
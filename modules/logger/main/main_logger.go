@@ -2,10 +2,14 @@ package main_logger
 
 import (
 	console_logger "github.com/go-api-template/go-backend/modules/logger/console"
+	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 )
 
 func init() {
 	// console logger
 	log.Logger = console_logger.NewConsoleLogger()
+
+	// log.Ctx(ctx) returns the console logger when the context holds no logger, such as outside the requests
+	zerolog.DefaultContextLogger = &log.Logger
 }
@@ -0,0 +1,26 @@
+package request_logger
+
+import (
+	"context"
+	"github.com/rs/zerolog/log"
+)
+
+// requestIdKey is the key of the request id in the context
+type requestIdKey struct{}
+
+// WithContext returns a copy of the context holding the id of the request and a logger bound to it
+// The events logged with log.Ctx(ctx) then carry the request id, and the trace ids if the request is traced
+func WithContext(ctx context.Context, requestId string) context.Context {
+	ctx = context.WithValue(ctx, requestIdKey{}, requestId)
+	return log.Logger.With().
+		Str("request_id", requestId).
+		Ctx(ctx).
+		Logger().
+		WithContext(ctx)
+}
+
+// RequestId returns the id of the request held by the context, or an empty string
+func RequestId(ctx context.Context) string {
+	id, _ := ctx.Value(requestIdKey{}).(string)
+	return id
+}
@@ -3,9 +3,21 @@ package mailer
 import (
 	"context"
 	"crypto/tls"
+	"errors"
+	"fmt"
 	"github.com/go-api-template/go-backend/modules/config"
+	"github.com/go-api-template/go-backend/modules/health"
+	request_logger "github.com/go-api-template/go-backend/modules/logger/request"
+	"github.com/go-api-template/go-backend/modules/metrics"
+	"github.com/go-api-template/go-backend/modules/tracing"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
 	"gopkg.in/gomail.v2"
+	"net"
+	"strconv"
 	"sync"
 )
 
@@ -14,14 +26,27 @@ import (
 var mailer *Mailer
 var once sync.Once
 
+var (
+	mailsSent = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "mailer_mails_sent_total",
+		Help: "Number of mails sent.",
+	})
+	mailsFailed = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "mailer_mails_failed_total",
+		Help: "Number of mails which could not be sent, including the mails dropped when the queue is full or the mailer is stopped.",
+	})
+)
+
 // Mailer is used to send emails
 type Mailer struct {
 	ctx      context.Context
 	finished context.CancelFunc
-	mutex    sync.Mutex
+	mutex    sync.RWMutex
 
 	mailChannel chan *Message
+	stopped     chan struct{}
 	isStarted   bool
+	isStopping  bool
 	isRunning   bool
 }
 
@@ -36,11 +61,57 @@ func NewMailer(ctx context.Context) *Mailer {
 			ctx:         ctx,
 			finished:    cancel,
 			mailChannel: make(chan *Message, 100),
+			stopped:     make(chan struct{}),
 		}
+		mailer.registerHealthChecks()
+		mailer.registerMetrics()
 	})
 	return mailer
 }
 
+// registerMetrics exposes the length of the queue and the number of mails sent and failed
+func (m *Mailer) registerMetrics() {
+	metrics.Registry.MustRegister(
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name: "mailer_queue_length",
+			Help: "Number of mails waiting to be sent.",
+		}, func() float64 {
+			return float64(m.Count())
+		}),
+		mailsSent,
+		mailsFailed,
+	)
+}
+
+// registerHealthChecks checks the queue and the smtp server in the readiness probe
+func (m *Mailer) registerHealthChecks() {
+	health.Register(health.Check{
+		Name: "mail-queue",
+		Checker: func(context.Context) error {
+			if count := m.Count(); count >= cap(m.mailChannel) {
+				return fmt.Errorf("the mail queue is full with %d mails", count)
+			}
+			return nil
+		},
+	})
+
+	smtp := config.Config.Mailer.Smtp
+	if smtp.Protocol == "dummy" {
+		return
+	}
+	health.Register(health.Check{
+		Name: "smtp",
+		Checker: func(ctx context.Context) error {
+			var dialer net.Dialer
+			conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(smtp.Host, strconv.Itoa(smtp.Port)))
+			if err != nil {
+				return err
+			}
+			return conn.Close()
+		},
+	})
+}
+
 // Start starts the mailer
 func (m *Mailer) Start() {
 	// prevent multiple start
@@ -48,8 +119,16 @@ func (m *Mailer) Start() {
 		return
 	}
 	// start the goroutine which listens to the mail channel
+	// it stops once the channel is closed and drained
 	go func() {
+		defer close(m.stopped)
 		for message := range m.mailChannel {
+			// the remaining mails are dropped if the mailer is stopped before the queue is drained
+			if m.ctx.Err() != nil {
+				log.Error().Strs("to", message.Recipients()).Str("subject", message.Subject).Str("request_id", message.requestId).Msg("Mail not sent, the mailer is stopped")
+				mailsFailed.Inc()
+				continue
+			}
 			m.sendMail(message)
 			if len(m.mailChannel) == 0 {
 				m.isRunning = false
@@ -61,8 +140,33 @@ func (m *Mailer) Start() {
 }
 
 // Stop stops the mail service
-func (m *Mailer) Stop() {
-	m.finished()
+// The queued mails are sent before it returns, unless the context is done first:
+// it then returns at once, the mail being sent is abandoned and the remaining mails are logged and dropped
+func (m *Mailer) Stop(ctx context.Context) error {
+	// stop accepting new mails
+	m.mutex.Lock()
+	if m.isStopping {
+		m.mutex.Unlock()
+		return nil
+	}
+	m.isStopping = true
+	close(m.mailChannel)
+	m.mutex.Unlock()
+
+	if !m.isStarted {
+		m.finished()
+		return nil
+	}
+
+	// wait for the queued mails
+	select {
+	case <-m.stopped:
+		m.finished()
+		return nil
+	case <-ctx.Done():
+		m.finished()
+		return ctx.Err()
+	}
 }
 
 // Count returns the number of messages in the mail channel
@@ -76,11 +180,24 @@ func (m *Mailer) IsRunning() bool {
 }
 
 // SendMail adds a message to the mail channel
-func (m *Mailer) SendMail(message *Message) {
-	m.mutex.Lock()
-	defer m.mutex.Unlock()
-	m.isRunning = true
-	m.mailChannel <- message
+// The trace and the request id of the context are carried by the message, so its send is part of the request.
+// An error is returned if the mailer is stopping or if the queue is full, it never blocks the caller
+func (m *Mailer) SendMail(ctx context.Context, message *Message) error {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	if m.isStopping {
+		return errors.New("the mailer is stopped")
+	}
+	message.spanContext = trace.SpanContextFromContext(ctx)
+	message.requestId = request_logger.RequestId(ctx)
+	select {
+	case m.mailChannel <- message:
+		m.isRunning = true
+		return nil
+	default:
+		mailsFailed.Inc()
+		return errors.New("the mail queue is full")
+	}
 }
 
 // sendMail sends the mail
@@ -91,13 +208,31 @@ func (m *Mailer) sendMail(message *Message) {
 	// get the mailer config
 	config := config.Config.Mailer.Smtp
 
+	// trace and log the send as part of the request which queued the mail
+	ctx, span := tracing.Tracer().Start(trace.ContextWithSpanContext(m.ctx, message.spanContext), "smtp send",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.ServerAddress(config.Host),
+			semconv.ServerPort(config.Port),
+			attribute.Int("mail.recipients", len(message.To)),
+		),
+	)
+	if message.requestId != "" {
+		ctx = request_logger.WithContext(ctx, message.requestId)
+	}
+
 	// create a new dialer
 	dialer := gomail.NewDialer(config.Host, config.Port, config.Username, config.Password)
 	// #nosec G402
 	dialer.TLSConfig = &tls.Config{InsecureSkipVerify: true}
 
 	// send the email
-	if err := dialer.DialAndSend(mailMessage); err != nil {
-		log.Error().Err(err).Msg("Failed to send verification code")
+	err := dialer.DialAndSend(mailMessage)
+	tracing.End(span, err)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Strs("to", message.Recipients()).Str("subject", message.Subject).Msg("Failed to send the mail")
+		mailsFailed.Inc()
+		return
 	}
+	mailsSent.Inc()
 }
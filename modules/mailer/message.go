@@ -2,6 +2,7 @@ package mailer
 
 import (
 	"github.com/go-api-template/go-backend/modules/config"
+	"go.opentelemetry.io/otel/trace"
 	"gopkg.in/gomail.v2"
 	"jaytaylor.com/html2text"
 	"net/mail"
@@ -17,6 +18,12 @@ type Message struct {
 	Subject string
 	Body    string
 	Headers map[string][]string
+
+	// spanContext is the span of the request which queued the message,
+	// the span of the send is its child although it is sent in background
+	spanContext trace.SpanContext
+	// requestId is the id of the request which queued the message, it is logged if the send fails
+	requestId string
 }
 
 // NewMessage creates a new Message
@@ -50,6 +57,15 @@ func (m *Message) SetHeader(field string, value ...string) {
 	m.Headers[field] = value
 }
 
+// Recipients returns the addresses of the recipients
+func (m *Message) Recipients() []string {
+	recipients := make([]string, len(m.To))
+	for i, to := range m.To {
+		recipients[i] = to.Address
+	}
+	return recipients
+}
+
 // ToMessage converts a Message to gomail.Message
 func ToMessage(message *Message) *gomail.Message {
 	// create a new gomail.Message
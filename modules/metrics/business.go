@@ -0,0 +1,23 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	// SignUps counts the accounts created, by method: form or invitation
+	SignUps = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "auth_signups_total",
+		Help: "Number of accounts created, by sign up method.",
+	}, []string{"method"})
+
+	// SignIns counts the sign in attempts, by result: success, invalid_credentials or not_verified
+	SignIns = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "auth_signins_total",
+		Help: "Number of sign in attempts, by result.",
+	}, []string{"result"})
+)
+
+func init() {
+	Registry.MustRegister(SignUps, SignIns)
+}
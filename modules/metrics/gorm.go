@@ -0,0 +1,78 @@
+package metrics
+
+import (
+	"errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"gorm.io/gorm"
+	"time"
+)
+
+var (
+	queryDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "db_query_duration_seconds",
+		Help:    "Duration of the database queries, by operation and table.",
+		Buckets: []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"operation", "table"})
+
+	queryErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "db_query_errors_total",
+		Help: "Number of the database queries which failed, by operation and table.",
+	}, []string{"operation", "table"})
+)
+
+func init() {
+	Registry.MustRegister(queryDuration, queryErrors)
+}
+
+// startedAtKey is the key of the start time of a query in the gorm statement
+const startedAtKey = "metrics:started_at"
+
+// GormPlugin times the queries of gorm, it is added with gormDb.Use
+type GormPlugin struct{}
+
+// Name returns the name of the plugin
+func (GormPlugin) Name() string {
+	return "metrics"
+}
+
+// Initialize registers the callbacks which time the queries
+// They run first and last, so the durations include the other callbacks, such as the hooks of the models
+func (p GormPlugin) Initialize(db *gorm.DB) error {
+	callback := db.Callback()
+	return errors.Join(
+		callback.Create().Before("*").Register("metrics:before_create", p.before),
+		callback.Create().After("*").Register("metrics:after_create", p.after("create")),
+		callback.Query().Before("*").Register("metrics:before_query", p.before),
+		callback.Query().After("*").Register("metrics:after_query", p.after("query")),
+		callback.Update().Before("*").Register("metrics:before_update", p.before),
+		callback.Update().After("*").Register("metrics:after_update", p.after("update")),
+		callback.Delete().Before("*").Register("metrics:before_delete", p.before),
+		callback.Delete().After("*").Register("metrics:after_delete", p.after("delete")),
+		callback.Row().Before("*").Register("metrics:before_row", p.before),
+		callback.Row().After("*").Register("metrics:after_row", p.after("row")),
+		callback.Raw().Before("*").Register("metrics:before_raw", p.before),
+		callback.Raw().After("*").Register("metrics:after_raw", p.after("raw")),
+	)
+}
+
+// before stores the start time of the query
+func (GormPlugin) before(db *gorm.DB) {
+	db.InstanceSet(startedAtKey, time.Now())
+}
+
+// after observes the duration of the query
+// A record which is not found is not counted as an error
+func (GormPlugin) after(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		value, ok := db.InstanceGet(startedAtKey)
+		if !ok {
+			return
+		}
+		startedAt, _ := value.(time.Time)
+		table := db.Statement.Table
+		queryDuration.WithLabelValues(operation, table).Observe(time.Since(startedAt).Seconds())
+		if db.Error != nil && !errors.Is(db.Error, gorm.ErrRecordNotFound) {
+			queryErrors.WithLabelValues(operation, table).Inc()
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"net/http"
+)
+
+// Registry holds the metrics exposed to Prometheus
+// The components register their own metrics, as they register their health checks
+var Registry = prometheus.NewRegistry()
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+}
+
+// Handler returns the handler of the /metrics endpoint, in the Prometheus text format
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{
+		Registry:          Registry,
+		EnableOpenMetrics: true,
+	})
+}
+
+// status returns the label of the result of an operation
+func status(err error) string {
+	if err != nil {
+		return "error"
+	}
+	return "ok"
+}
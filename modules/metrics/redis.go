@@ -0,0 +1,114 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"github.com/go-redis/redis/v8"
+	"github.com/prometheus/client_golang/prometheus"
+	"time"
+)
+
+var (
+	redisCommands = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "redis_commands_total",
+		Help: "Number of the Redis commands, by command and status.",
+	}, []string{"command", "status"})
+
+	redisDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "redis_command_duration_seconds",
+		Help:    "Duration of the Redis commands, by command. The pipelines are timed as a whole.",
+		Buckets: []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1},
+	}, []string{"command"})
+)
+
+func init() {
+	Registry.MustRegister(redisCommands, redisDuration)
+}
+
+// redisStartedAtKey is the key of the start time of a command in its context
+type redisStartedAtKey struct{}
+
+// RedisHook counts and times the Redis commands, it is added with client.AddHook
+type RedisHook struct{}
+
+func (RedisHook) BeforeProcess(ctx context.Context, _ redis.Cmder) (context.Context, error) {
+	return context.WithValue(ctx, redisStartedAtKey{}, time.Now()), nil
+}
+
+func (h RedisHook) AfterProcess(ctx context.Context, cmd redis.Cmder) error {
+	h.observe(ctx, cmd.Name())
+	h.count(cmd)
+	return nil
+}
+
+func (RedisHook) BeforeProcessPipeline(ctx context.Context, _ []redis.Cmder) (context.Context, error) {
+	return context.WithValue(ctx, redisStartedAtKey{}, time.Now()), nil
+}
+
+func (h RedisHook) AfterProcessPipeline(ctx context.Context, cmds []redis.Cmder) error {
+	h.observe(ctx, "pipeline")
+	for _, cmd := range cmds {
+		h.count(cmd)
+	}
+	return nil
+}
+
+// observe observes the duration of a command
+func (RedisHook) observe(ctx context.Context, command string) {
+	if startedAt, ok := ctx.Value(redisStartedAtKey{}).(time.Time); ok {
+		redisDuration.WithLabelValues(command).Observe(time.Since(startedAt).Seconds())
+	}
+}
+
+// count counts a command, a missing key is not an error
+func (RedisHook) count(cmd redis.Cmder) {
+	err := cmd.Err()
+	if errors.Is(err, redis.Nil) {
+		err = nil
+	}
+	redisCommands.WithLabelValues(cmd.Name(), status(err)).Inc()
+}
+
+// redisPoolCollector collects the statistics of the pool of connections of a Redis client
+type redisPoolCollector struct {
+	client *redis.Client
+
+	hits       *prometheus.Desc
+	misses     *prometheus.Desc
+	timeouts   *prometheus.Desc
+	totalConns *prometheus.Desc
+	idleConns  *prometheus.Desc
+	staleConns *prometheus.Desc
+}
+
+// NewRedisPoolCollector returns a collector of the statistics of the pool of connections of a Redis client
+func NewRedisPoolCollector(client *redis.Client) prometheus.Collector {
+	return &redisPoolCollector{
+		client:     client,
+		hits:       prometheus.NewDesc("redis_pool_hits_total", "Number of times a free connection was found in the pool.", nil, nil),
+		misses:     prometheus.NewDesc("redis_pool_misses_total", "Number of times a free connection was not found in the pool.", nil, nil),
+		timeouts:   prometheus.NewDesc("redis_pool_timeouts_total", "Number of times a wait for a connection timed out.", nil, nil),
+		totalConns: prometheus.NewDesc("redis_pool_connections", "Number of connections in the pool.", nil, nil),
+		idleConns:  prometheus.NewDesc("redis_pool_idle_connections", "Number of idle connections in the pool.", nil, nil),
+		staleConns: prometheus.NewDesc("redis_pool_stale_connections_total", "Number of stale connections removed from the pool.", nil, nil),
+	}
+}
+
+func (c *redisPoolCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.hits
+	ch <- c.misses
+	ch <- c.timeouts
+	ch <- c.totalConns
+	ch <- c.idleConns
+	ch <- c.staleConns
+}
+
+func (c *redisPoolCollector) Collect(ch chan<- prometheus.Metric) {
+	stats := c.client.PoolStats()
+	ch <- prometheus.MustNewConstMetric(c.hits, prometheus.CounterValue, float64(stats.Hits))
+	ch <- prometheus.MustNewConstMetric(c.misses, prometheus.CounterValue, float64(stats.Misses))
+	ch <- prometheus.MustNewConstMetric(c.timeouts, prometheus.CounterValue, float64(stats.Timeouts))
+	ch <- prometheus.MustNewConstMetric(c.totalConns, prometheus.GaugeValue, float64(stats.TotalConns))
+	ch <- prometheus.MustNewConstMetric(c.idleConns, prometheus.GaugeValue, float64(stats.IdleConns))
+	ch <- prometheus.MustNewConstMetric(c.staleConns, prometheus.CounterValue, float64(stats.StaleConns))
+}
@@ -0,0 +1,105 @@
+package middlewares
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/go-api-template/go-backend/modules/config"
+	access_logger "github.com/go-api-template/go-backend/modules/logger/access"
+	api "github.com/go-api-template/go-backend/modules/utils/api"
+	"github.com/rs/zerolog"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// redacted replaces the values of the redacted query parameters
+const redacted = "REDACTED"
+
+// AccessLogger writes a structured event for each request in the access log
+// The successful requests are sampled, the failed ones are always logged.
+// The url is logged as its route template, such as /invitations/:token/signup, so the tokens of the paths
+// are never written, and the values of the sensitive query parameters are redacted
+func AccessLogger() gin.HandlerFunc {
+	c := config.Config.Logs.Access
+	base := ""
+	if config.Config.Server.BasePath != "" {
+		base = "/" + config.Config.Server.BasePath
+	}
+
+	return func(ctx *gin.Context) {
+		path := ctx.Request.URL.Path
+		if isExcluded(strings.TrimPrefix(path, base), c.Exclude) {
+			ctx.Next()
+			return
+		}
+
+		start := time.Now()
+		ctx.Next()
+		latency := time.Since(start)
+
+		status := ctx.Writer.Status()
+		if status < http.StatusBadRequest && rand.Float64() >= c.SampleRate {
+			return
+		}
+
+		var event *zerolog.Event
+		switch {
+		case status >= http.StatusInternalServerError:
+			event = access_logger.AccessLogger.Error()
+		case status >= http.StatusBadRequest:
+			event = access_logger.AccessLogger.Warn()
+		default:
+			event = access_logger.AccessLogger.Info()
+		}
+
+		event = event.Ctx(ctx.Request.Context()).
+			Str("method", ctx.Request.Method).
+			Int("status", status).
+			Dur("latency", latency).
+			Int("bytes", max(ctx.Writer.Size(), 0)).
+			Str("ip", ctx.ClientIP()).
+			Str("user_agent", ctx.Request.UserAgent()).
+			Str("request_id", ctx.GetString(api.RequestIdKey))
+		if route := ctx.FullPath(); route != "" {
+			event = event.Str("route", route)
+		} else {
+			event = event.Str("path", path)
+		}
+		if ctx.Request.URL.RawQuery != "" {
+			event = event.Str("query", redactQuery(ctx.Request.URL.Query(), c.Redact))
+		}
+		if user, err := GetUserFromContext(ctx); err == nil {
+			event = event.Str("user_id", user.ID.String())
+		}
+		if len(ctx.Errors) > 0 {
+			event = event.Str("error", ctx.Errors.String())
+		}
+		event.Msg("Request")
+	}
+}
+
+// isExcluded returns true if the path is one of the excluded paths or below one of them
+func isExcluded(path string, exclude []string) bool {
+	for _, excluded := range exclude {
+		excluded = strings.TrimSuffix(excluded, "/")
+		if excluded != "" && (path == excluded || strings.HasPrefix(path, excluded+"/")) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactQuery returns the query with the values of the redacted parameters hidden
+func redactQuery(query url.Values, redact []string) string {
+	for name := range query {
+		for _, sensitive := range redact {
+			if strings.EqualFold(name, sensitive) {
+				for i := range query[name] {
+					query[name][i] = redacted
+				}
+			}
+		}
+	}
+	return query.Encode()
+}
@@ -86,7 +86,7 @@ func (a Authorizer) contextUser(ctx *gin.Context) (*models.User, error) {
 	}
 
 	// Get the user from the database
-	user, err := a.userService.FindById(subscriberUUID)
+	user, err := a.userService.WithContext(ctx.Request.Context()).FindById(subscriberUUID)
 	if err != nil {
 		return nil, errors.New("the user belonging to this token no longer exists")
 	}
@@ -106,11 +106,20 @@ func ContextUser() gin.HandlerFunc {
 			return
 		}
 
-		ctx.Set(CtxUser, user)
+		setContextUser(ctx, user)
 		ctx.Next()
 	}
 }
 
+// setContextUser adds the user to the context
+// The responses are translated in the locale chosen by the user, if any
+func setContextUser(ctx *gin.Context, user *models.User) {
+	ctx.Set(CtxUser, user)
+	if user != nil && user.Locale != "" {
+		setLocale(ctx, user.Locale)
+	}
+}
+
 // GetUserFromContext extracts the user from the context
 func GetUserFromContext(ctx *gin.Context) (*models.User, error) {
 	// Get the user from the context
@@ -134,7 +143,7 @@ func VerifiedUser() gin.HandlerFunc {
 		user, err := authorizer.contextUser(ctx)
 		if err != nil {
 			api.Ctx(ctx).Unauthorized().
-				WithCode("user_error").
+				WithCode(api.CodeNotAuthenticated).
 				WithDescription("You are not logged in").
 				WithError(err).
 				Send()
@@ -145,7 +154,7 @@ func VerifiedUser() gin.HandlerFunc {
 		// Check if the user exists
 		if user == nil {
 			api.Ctx(ctx).Unauthorized().
-				WithCode("user_error").
+				WithCode(api.CodeUserNotFound).
 				WithDescription("User not found").
 				Send()
 			ctx.Abort()
@@ -155,19 +164,19 @@ func VerifiedUser() gin.HandlerFunc {
 		// Check if the user is verified
 		if !user.Verified {
 			api.Ctx(ctx).Unauthorized().
-				WithCode("user_error").
+				WithCode(api.CodeAccountNotVerified).
 				WithDescription("Your account is not verified").
 				Send()
 			ctx.Abort()
 			return
 		}
 
-		ctx.Set(CtxUser, user)
+		setContextUser(ctx, user)
 		ctx.Next()
 	}
 }
 
-// AdminUser checks if the user is an admin
+// AdminUser checks if the user is a platform admin
 func AdminUser() gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		// Get the user from the context
@@ -183,7 +192,7 @@ func AdminUser() gin.HandlerFunc {
 		// Check if the user exists
 		if user == nil {
 			api.Ctx(ctx).Unauthorized().
-				WithCode("user_error").
+				WithCode(api.CodeUserNotFound).
 				WithDescription("User not found").
 				Send()
 			ctx.Abort()
@@ -193,14 +202,14 @@ func AdminUser() gin.HandlerFunc {
 		// Check if the user is an admin
 		if !user.Role.IsAdmin() {
 			api.Ctx(ctx).Unauthorized().
-				WithCode("user_error").
+				WithCode(api.CodePlatformAdminRequired).
 				WithDescription("You are not an admin").
 				Send()
 			ctx.Abort()
 			return
 		}
 
-		ctx.Set(CtxUser, user)
+		setContextUser(ctx, user)
 		ctx.Next()
 	}
 }
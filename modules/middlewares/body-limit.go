@@ -0,0 +1,26 @@
+package middlewares
+
+import (
+	"github.com/gin-gonic/gin"
+	api "github.com/go-api-template/go-backend/modules/utils/api"
+	"net/http"
+)
+
+// LimitBody limits the size of the request bodies
+// The requests announcing a larger body are rejected at once,
+// the others fail when the handler reads more than the limit
+func LimitBody(maxBytes int64) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if maxBytes <= 0 || ctx.Request.Body == nil || ctx.Request.Body == http.NoBody {
+			ctx.Next()
+			return
+		}
+		if ctx.Request.ContentLength > maxBytes {
+			api.SendBodyTooLarge(ctx, maxBytes)
+			ctx.Abort()
+			return
+		}
+		ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, maxBytes)
+		ctx.Next()
+	}
+}
@@ -9,7 +9,9 @@ import (
 func Cors() gin.HandlerFunc {
 	// Cors config
 	corsConfig := cors.DefaultConfig()
-	corsConfig.AddAllowHeaders("Authorization")
+	corsConfig.AddAllowHeaders("Authorization", "If-Match", "If-None-Match", HeaderIdempotencyKey, HeaderRequestId)
+	corsConfig.AddExposeHeaders("ETag", HeaderIdempotentReplayed, HeaderRequestId,
+		HeaderRateLimitLimit, HeaderRateLimitRemaining, HeaderRateLimitReset, HeaderRateLimitPolicy, HeaderRetryAfter)
 
 	// Allow all origins while in debug mode
 	if config.Config.App.Debug {
@@ -0,0 +1,266 @@
+package middlewares
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/go-api-template/go-backend/modules/config"
+	api "github.com/go-api-template/go-backend/modules/utils/api"
+	"github.com/go-redis/redis/v8"
+	"github.com/rs/zerolog/log"
+	"io"
+	"net/http"
+	"slices"
+	"time"
+)
+
+type Idempotency struct {
+	redis *redis.Client
+}
+
+var (
+	idempotency *Idempotency
+
+	// HeaderIdempotencyKey is the header used by the clients to retry a request safely
+	HeaderIdempotencyKey = "Idempotency-Key"
+	// HeaderIdempotentReplayed is set on the responses replayed for a retried request
+	HeaderIdempotentReplayed = "Idempotent-Replayed"
+
+	// idempotentMethods are the unsafe methods whose responses are stored
+	idempotentMethods = []string{http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete}
+	// replayedHeaders are the headers of the response which are stored with its body
+	// The cookies of the sign in and of the refresh are replayed too: a retry must send the same credentials,
+	// in its headers for the storage key or in its body for the fingerprint, to get them
+	replayedHeaders = []string{"Content-Type", "Content-Language", "Location", "ETag", "Link", "Set-Cookie"}
+)
+
+// maxIdempotencyKeyLength is the maximum length of an Idempotency-Key
+const maxIdempotencyKeyLength = 255
+
+// idempotencyTimeout bounds the time spent storing a response or releasing a key
+const idempotencyTimeout = 5 * time.Second
+
+// idempotentResponse is the response of a request stored in Redis
+// It is stored without status while the request is being processed
+type idempotentResponse struct {
+	Fingerprint string              `json:"fingerprint"`
+	Status      int                 `json:"status,omitempty"`
+	Header      map[string][]string `json:"header,omitempty"`
+	Body        []byte              `json:"body,omitempty"`
+}
+
+// capturingWriter keeps a copy of the body written to the client
+type capturingWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *capturingWriter) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *capturingWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// InitializeIdempotency initializes the storage of the idempotent responses
+func InitializeIdempotency(redis *redis.Client) {
+	idempotency = &Idempotency{redis}
+}
+
+// IdempotencyKey makes the unsafe requests sent with an Idempotency-Key header safe to retry
+// The response of the first request is stored and replayed on the retries with the same key,
+// a retry with the same key but another method, path or body is rejected
+func IdempotencyKey() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		key := ctx.GetHeader(HeaderIdempotencyKey)
+		if key == "" || idempotency == nil || !slices.Contains(idempotentMethods, ctx.Request.Method) {
+			ctx.Next()
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			api.Ctx(ctx).BadRequest().
+				WithCode(api.CodeInvalidIdempotencyKey).
+				WithDescription("The Idempotency-Key header is too long").
+				Send()
+			ctx.Abort()
+			return
+		}
+
+		// Read the body to compute the fingerprint of the request, then restore it for the handlers
+		body, err := io.ReadAll(ctx.Request.Body)
+		if err != nil {
+			api.SendBodyError(ctx, err)
+			ctx.Abort()
+			return
+		}
+		ctx.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		i := idempotency
+		storageKey := i.storageKey(ctx, key)
+		fingerprint := i.fingerprint(ctx, body)
+
+		// Reserve the key, unless a request has already been sent with it
+		stored, err := i.reserve(ctx, storageKey, fingerprint)
+		if err != nil {
+			// The request is handled without protection rather than rejected when Redis is unavailable
+			log.Ctx(ctx.Request.Context()).Warn().Err(err).Msg("Reserving the idempotency key")
+			ctx.Next()
+			return
+		}
+		if stored != nil {
+			switch {
+			case stored.Fingerprint != fingerprint:
+				api.Ctx(ctx).Conflict().
+					WithCode(api.CodeIdempotencyKeyReused).
+					WithDescription("The Idempotency-Key has already been used with another request").
+					Send()
+			case stored.Status == 0:
+				api.Ctx(ctx).Conflict().
+					WithCode(api.CodeIdempotencyKeyInUse).
+					WithDescription("A request with the same Idempotency-Key is being processed").
+					Send()
+			default:
+				for name, values := range stored.Header {
+					for _, value := range values {
+						ctx.Writer.Header().Add(name, value)
+					}
+				}
+				ctx.Header(HeaderIdempotentReplayed, "true")
+				ctx.Status(stored.Status)
+				_, _ = ctx.Writer.Write(stored.Body)
+			}
+			ctx.Abort()
+			return
+		}
+
+		// The key is released unless the response is stored, even if a handler panics,
+		// so the request can be retried
+		completed := false
+		defer func() {
+			if !completed {
+				i.release(ctx, storageKey)
+			}
+		}()
+
+		// Handle the request and capture its response
+		writer := &capturingWriter{ResponseWriter: ctx.Writer}
+		ctx.Writer = writer
+		ctx.Next()
+
+		// The responses to server errors and to requests over the rate limit are not stored
+		if writer.Status() >= http.StatusInternalServerError || writer.Status() == http.StatusTooManyRequests {
+			return
+		}
+		completed = i.store(ctx, storageKey, &idempotentResponse{
+			Fingerprint: fingerprint,
+			Status:      writer.Status(),
+			Header:      i.header(writer.Header()),
+			Body:        writer.body.Bytes(),
+		})
+	}
+}
+
+// storageKey returns the Redis key of an Idempotency-Key
+// The keys of different clients never collide as the credentials of the request are part of it
+func (i *Idempotency) storageKey(ctx *gin.Context, key string) string {
+	hash := sha256.New()
+	hash.Write([]byte(key))
+	hash.Write([]byte{0})
+	hash.Write([]byte(ctx.GetHeader("Authorization")))
+	if cookie, err := ctx.Cookie("access_token"); err == nil {
+		hash.Write([]byte{0})
+		hash.Write([]byte(cookie))
+	}
+	return "idempotency:" + hex.EncodeToString(hash.Sum(nil))
+}
+
+// fingerprint identifies the request sent with an Idempotency-Key
+func (i *Idempotency) fingerprint(ctx *gin.Context, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(ctx.Request.Method))
+	hash.Write([]byte{0})
+	hash.Write([]byte(ctx.Request.URL.RequestURI()))
+	hash.Write([]byte{0})
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// reserve stores the fingerprint of the request if the key is not used yet
+// The response stored with the key is returned if it is already used
+func (i *Idempotency) reserve(ctx *gin.Context, storageKey string, fingerprint string) (*idempotentResponse, error) {
+	data, err := json.Marshal(&idempotentResponse{Fingerprint: fingerprint})
+	if err != nil {
+		return nil, err
+	}
+	reserved, err := i.redis.SetNX(ctx.Request.Context(), storageKey, data, i.maxAge()).Result()
+	if err != nil || reserved {
+		return nil, err
+	}
+
+	data, err = i.redis.Get(ctx.Request.Context(), storageKey).Bytes()
+	if errors.Is(err, redis.Nil) {
+		// The key has expired in the meantime
+		return i.reserve(ctx, storageKey, fingerprint)
+	}
+	if err != nil {
+		return nil, err
+	}
+	stored := &idempotentResponse{}
+	if err := json.Unmarshal(data, stored); err != nil {
+		return nil, err
+	}
+	return stored, nil
+}
+
+// store stores the response of the request and returns true if it succeeded
+func (i *Idempotency) store(ctx *gin.Context, storageKey string, response *idempotentResponse) bool {
+	data, err := json.Marshal(response)
+	if err == nil {
+		c, cancel := i.context(ctx)
+		defer cancel()
+		err = i.redis.Set(c, storageKey, data, i.maxAge()).Err()
+	}
+	if err != nil {
+		log.Ctx(ctx.Request.Context()).Warn().Err(err).Msg("Storing the idempotent response")
+		return false
+	}
+	return true
+}
+
+// release removes the key, so the request can be sent again
+func (i *Idempotency) release(ctx *gin.Context, storageKey string) {
+	c, cancel := i.context(ctx)
+	defer cancel()
+	if err := i.redis.Del(c, storageKey).Err(); err != nil {
+		log.Ctx(ctx.Request.Context()).Warn().Err(err).Msg("Releasing the idempotency key")
+	}
+}
+
+// context returns the context of the commands run once the request has been handled
+// They must not be canceled with the request, otherwise the key would stay reserved
+func (i *Idempotency) context(ctx *gin.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.WithoutCancel(ctx.Request.Context()), idempotencyTimeout)
+}
+
+// header returns the headers of the response which are replayed
+func (i *Idempotency) header(header http.Header) map[string][]string {
+	replayed := map[string][]string{}
+	for _, name := range replayedHeaders {
+		if values := header.Values(name); len(values) > 0 {
+			replayed[name] = values
+		}
+	}
+	return replayed
+}
+
+// maxAge returns how long the responses are stored
+func (i *Idempotency) maxAge() time.Duration {
+	return time.Duration(config.Config.Idempotency.MaxAge) * time.Hour
+}
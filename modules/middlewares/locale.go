@@ -0,0 +1,22 @@
+package middlewares
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/go-api-template/go-backend/modules/i18n"
+)
+
+// Locale negotiates the locale of the request with the Accept-Language header
+// The locale of the connected user replaces it once the user is known
+func Locale() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ctx.Header("Vary", "Accept-Language")
+		setLocale(ctx, i18n.Negotiate(ctx.GetHeader("Accept-Language")))
+		ctx.Next()
+	}
+}
+
+// setLocale sets the locale used to translate the response
+func setLocale(ctx *gin.Context, locale string) {
+	ctx.Set(i18n.LocaleKey, locale)
+	ctx.Header("Content-Language", locale)
+}
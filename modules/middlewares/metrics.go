@@ -0,0 +1,64 @@
+package middlewares
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/go-api-template/go-backend/modules/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+)
+
+var (
+	httpRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_requests_total",
+		Help: "Number of the http requests, by method, route and status.",
+	}, []string{"method", "route", "status"})
+
+	httpDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_request_duration_seconds",
+		Help:    "Duration of the http requests, by method, route and status.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	httpInFlight = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "http_requests_in_flight",
+		Help: "Number of the http requests being handled.",
+	})
+
+	// httpMethods are the methods used as label, the other ones are grouped as "other"
+	httpMethods = []string{
+		http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut,
+		http.MethodPatch, http.MethodDelete, http.MethodOptions,
+	}
+)
+
+func init() {
+	metrics.Registry.MustRegister(httpRequests, httpDuration, httpInFlight)
+}
+
+// Metrics counts and times the requests by route template and status
+// The route is the template of the url, such as /users/:id, so each user does not create a new series.
+// The requests which match no route are grouped under the "unmatched" route
+func Metrics() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		start := time.Now()
+		httpInFlight.Inc()
+		defer httpInFlight.Dec()
+		ctx.Next()
+
+		method := ctx.Request.Method
+		if !slices.Contains(httpMethods, method) {
+			method = "other"
+		}
+		route := ctx.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		status := strconv.Itoa(ctx.Writer.Status())
+
+		httpRequests.WithLabelValues(method, route, status).Inc()
+		httpDuration.WithLabelValues(method, route, status).Observe(time.Since(start).Seconds())
+	}
+}
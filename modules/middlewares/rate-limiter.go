@@ -0,0 +1,183 @@
+package middlewares
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/go-api-template/go-backend/modules/config"
+	api "github.com/go-api-template/go-backend/modules/utils/api"
+	"github.com/go-api-template/go-backend/modules/utils/token"
+	"github.com/go-redis/redis/v8"
+	"github.com/rs/zerolog/log"
+	"strconv"
+	"time"
+)
+
+type RateLimiter struct {
+	redis *redis.Client
+}
+
+// RateLimit is the limit of the number of requests sent by a client
+type RateLimit struct {
+	// Name identifies the limit, the requests sent to the routes sharing a limit are counted together
+	Name string
+	// Requests is the number of requests allowed during the period
+	Requests int
+	// Period is the duration of the period
+	Period time.Duration
+	// Burst is the number of requests which can be sent at once, it is the number of requests by default
+	Burst int
+	// Key identifies the client sending the request
+	Key RateLimitKey
+	// PerRoute counts the requests sent to each route separately
+	PerRoute bool
+}
+
+// RateLimitKey returns the key identifying the client sending a request
+type RateLimitKey func(ctx *gin.Context) string
+
+var (
+	rateLimiter *RateLimiter
+
+	// Headers of the responses describing the limit, as defined by the RateLimit header fields draft
+	HeaderRateLimitLimit     = "RateLimit-Limit"
+	HeaderRateLimitRemaining = "RateLimit-Remaining"
+	HeaderRateLimitReset     = "RateLimit-Reset"
+	HeaderRateLimitPolicy    = "RateLimit-Policy"
+	HeaderRetryAfter         = "Retry-After"
+)
+
+// gcra is the generic cell rate algorithm
+// The theoretical arrival time of the next request is stored for each client, a request is allowed
+// if it does not arrive earlier than the theoretical arrival time minus the burst tolerance.
+// The time of Redis is used, so the servers do not need to have synchronized clocks.
+// The arguments are in milliseconds, it returns whether the request is allowed, the remaining requests,
+// the delay before a request is allowed and the delay before all the requests are allowed again
+var gcra = redis.NewScript(`
+redis.replicate_commands()
+local interval = tonumber(ARGV[1])
+local tolerance = tonumber(ARGV[2])
+local clock = redis.call("TIME")
+local now = tonumber(clock[1]) * 1000 + math.floor(tonumber(clock[2]) / 1000)
+local tat = math.max(tonumber(redis.call("GET", KEYS[1])) or now, now)
+local arrival = tat + interval
+local allowed = arrival - tolerance
+if now < allowed then
+	return {0, 0, allowed - now, tat - now}
+end
+redis.call("SET", KEYS[1], arrival, "PX", arrival - now)
+return {1, math.floor((now - allowed) / interval), 0, arrival - now}
+`)
+
+// InitializeRateLimiter initializes the storage of the rate limits
+func InitializeRateLimiter(redis *redis.Client) {
+	rateLimiter = &RateLimiter{redis}
+}
+
+// RateLimitByIP identifies the clients by their ip address
+func RateLimitByIP(ctx *gin.Context) string {
+	return "ip:" + ctx.ClientIP()
+}
+
+// RateLimitByUser identifies the clients by their user, or by their ip address when they are anonymous
+// The user is taken from the access token, the database is not queried
+func RateLimitByUser(ctx *gin.Context) string {
+	if authorizer != nil {
+		if accessToken, err := authorizer.authorization(ctx); err == nil {
+			if claims, err := token.Validate(accessToken, config.Config.Tokens.Access.PublicKey); err == nil {
+				if subject, err := claims.GetSubject(); err == nil {
+					return "user:" + subject
+				}
+			}
+		}
+	}
+	return RateLimitByIP(ctx)
+}
+
+// RateLimitByHeader identifies the clients by a header, such as an api key,
+// or by their ip address when the header is not sent
+func RateLimitByHeader(name string) RateLimitKey {
+	return func(ctx *gin.Context) string {
+		value := ctx.GetHeader(name)
+		if value == "" {
+			return RateLimitByIP(ctx)
+		}
+		hash := sha256.Sum256([]byte(value))
+		return "header:" + name + ":" + hex.EncodeToString(hash[:])
+	}
+}
+
+// LimitRate limits the number of requests sent by each client
+// The requests above the limit are rejected with a 429 status until enough time has passed
+func LimitRate(limit RateLimit) gin.HandlerFunc {
+	if limit.Burst <= 0 {
+		limit.Burst = limit.Requests
+	}
+	if limit.Key == nil {
+		limit.Key = RateLimitByIP
+	}
+	policy := fmt.Sprintf("%d;w=%d;burst=%d", limit.Requests, int(limit.Period.Seconds()), limit.Burst)
+
+	return func(ctx *gin.Context) {
+		if !config.Config.RateLimit.Enable || rateLimiter == nil || limit.Requests <= 0 {
+			ctx.Next()
+			return
+		}
+
+		allowed, remaining, retryAfter, reset, err := rateLimiter.take(ctx, limit)
+		if err != nil {
+			// The request is allowed rather than rejected when Redis is unavailable
+			log.Ctx(ctx.Request.Context()).Warn().Err(err).Str("limit", limit.Name).Msg("Checking the rate limit")
+			ctx.Next()
+			return
+		}
+
+		ctx.Header(HeaderRateLimitLimit, strconv.Itoa(limit.Burst))
+		ctx.Header(HeaderRateLimitRemaining, strconv.Itoa(remaining))
+		ctx.Header(HeaderRateLimitReset, strconv.Itoa(seconds(reset)))
+		ctx.Header(HeaderRateLimitPolicy, policy)
+		if !allowed {
+			ctx.Header(HeaderRetryAfter, strconv.Itoa(seconds(retryAfter)))
+			api.Ctx(ctx).TooManyRequests().
+				WithCode(api.CodeTooManyRequests).
+				WithDescription("Too many requests have been sent, please retry later").
+				Send()
+			ctx.Abort()
+			return
+		}
+
+		ctx.Next()
+	}
+}
+
+// take counts a request of the client against the limit
+func (l *RateLimiter) take(ctx *gin.Context, limit RateLimit) (allowed bool, remaining int, retryAfter time.Duration, reset time.Duration, err error) {
+	key := "ratelimit:" + limit.Name + ":"
+	if limit.PerRoute {
+		key += ctx.Request.Method + ":" + ctx.FullPath() + ":"
+	}
+	key += limit.Key(ctx)
+
+	interval := max(limit.Period.Milliseconds()/int64(limit.Requests), 1)
+	tolerance := interval * int64(limit.Burst)
+	result, err := gcra.Run(ctx.Request.Context(), l.redis, []string{key}, interval, tolerance).Int64Slice()
+	if err != nil {
+		return
+	}
+	if len(result) != 4 {
+		err = fmt.Errorf("unexpected result of the rate limit script: %v", result)
+		return
+	}
+
+	allowed = result[0] == 1
+	remaining = int(result[1])
+	retryAfter = time.Duration(result[2]) * time.Millisecond
+	reset = time.Duration(result[3]) * time.Millisecond
+	return
+}
+
+// seconds rounds up a delay to a number of seconds
+func seconds(d time.Duration) int {
+	return int((d + time.Second - 1) / time.Second)
+}
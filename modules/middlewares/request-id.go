@@ -0,0 +1,40 @@
+package middlewares
+
+import (
+	"github.com/gin-gonic/gin"
+	request_logger "github.com/go-api-template/go-backend/modules/logger/request"
+	api "github.com/go-api-template/go-backend/modules/utils/api"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"regexp"
+)
+
+var (
+	// HeaderRequestId is the header identifying a request in the logs, sent by the client or generated
+	HeaderRequestId = "X-Request-ID"
+
+	// requestIdPattern is the format of the request ids accepted from the clients,
+	// so they cannot inject anything in the logs
+	requestIdPattern = regexp.MustCompile(`^[A-Za-z0-9._:+/=-]{1,128}$`)
+)
+
+// RequestId identifies each request, so its logs can be found from a support ticket
+// The id sent by the client or by a proxy in the X-Request-ID header is kept, otherwise one is generated.
+// It is sent back in the X-Request-ID header and in the problems, and stored in the gin context
+// and in the context of the request, whose logger adds it to all the events, see log.Ctx
+func RequestId() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id := ctx.GetHeader(HeaderRequestId)
+		if !requestIdPattern.MatchString(id) {
+			id = uuid.New().String()
+		}
+
+		ctx.Set(api.RequestIdKey, id)
+		ctx.Header(HeaderRequestId, id)
+		ctx.Request = ctx.Request.WithContext(request_logger.WithContext(ctx.Request.Context(), id))
+		trace.SpanFromContext(ctx.Request.Context()).SetAttributes(attribute.String("http.request_id", id))
+
+		ctx.Next()
+	}
+}
@@ -0,0 +1,196 @@
+package middlewares
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"github.com/gin-gonic/gin"
+	"github.com/go-api-template/go-backend/modules/cache"
+	"github.com/go-api-template/go-backend/modules/i18n"
+	api "github.com/go-api-template/go-backend/modules/utils/api"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type ResponseCache struct {
+	cache *cache.Cache
+}
+
+var (
+	responseCache *ResponseCache
+
+	// cachedHeaders are the headers of the response which are stored with its body
+	cachedHeaders = []string{"Content-Type", "Content-Language", "ETag", "Link", "Cache-Control", "Vary", "Expires", "Last-Modified"}
+)
+
+// cachedResponse is a response stored in the cache
+type cachedResponse struct {
+	Status   int
+	Header   map[string][]string
+	Body     []byte
+	StoredAt time.Time
+}
+
+// InitializeResponseCache initializes the storage of the cached responses
+func InitializeResponseCache(cache *cache.Cache) {
+	responseCache = &ResponseCache{cache}
+}
+
+// CacheTag returns a tag of a cached response from its request, the response is not cached if it is empty
+type CacheTag func(ctx *gin.Context) string
+
+// CacheTagId returns the tag of the resource whose id is given in a path parameter, such as services.UserCacheTag
+func CacheTagId(tag func(id uuid.UUID) string, param string) CacheTag {
+	return func(ctx *gin.Context) string {
+		id, err := uuid.Parse(ctx.Param(param))
+		if err != nil {
+			return ""
+		}
+		return tag(id)
+	}
+}
+
+// CacheResponses caches the responses of the GET requests which allow it with their Cache-Control header
+// A response is stored if its Cache-Control header is public and gives a max-age or a s-maxage,
+// it is then sent again until it expires or until one of the tags is invalidated.
+// The responses to the authenticated users are cached for each user, they can also be private:
+// the middleware must then come after the authorization, which is checked on each request.
+// The clients can bypass the cache with the no-cache and no-store directives
+func CacheResponses(tags ...CacheTag) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if responseCache == nil || ctx.Request.Method != http.MethodGet {
+			ctx.Next()
+			return
+		}
+		request := cacheControl(ctx.GetHeader("Cache-Control"))
+		if _, ok := request["no-store"]; ok {
+			ctx.Next()
+			return
+		}
+
+		c := responseCache
+		key := c.key(ctx)
+
+		// Send the cached response
+		if _, ok := request["no-cache"]; !ok {
+			cached, found, err := cache.Get[cachedResponse](ctx.Request.Context(), c.cache, key)
+			if err != nil {
+				log.Ctx(ctx.Request.Context()).Warn().Err(err).Msg("Reading the cached response")
+			}
+			if found {
+				c.send(ctx, &cached)
+				ctx.Abort()
+				return
+			}
+		}
+
+		// Handle the request and capture its response
+		writer := &capturingWriter{ResponseWriter: ctx.Writer}
+		ctx.Writer = writer
+		ctx.Next()
+
+		_, userErr := GetUserFromContext(ctx)
+		ttl := c.ttl(writer, userErr == nil)
+		if ttl <= 0 {
+			return
+		}
+		names := make([]string, len(tags))
+		for i, tag := range tags {
+			if names[i] = tag(ctx); names[i] == "" {
+				return
+			}
+		}
+		header := map[string][]string{}
+		for _, name := range cachedHeaders {
+			if values := writer.Header().Values(name); len(values) > 0 {
+				header[name] = values
+			}
+		}
+		err := cache.Set(ctx.Request.Context(), c.cache, key, cachedResponse{
+			Status:   writer.Status(),
+			Header:   header,
+			Body:     writer.body.Bytes(),
+			StoredAt: time.Now(),
+		}, ttl, names...)
+		if err != nil {
+			log.Ctx(ctx.Request.Context()).Warn().Err(err).Msg("Storing the cached response")
+		}
+	}
+}
+
+// key returns the key of the response in the cache
+// The responses are translated, so the locale of the request is part of it,
+// and the responses to an authenticated user are only sent again to this user
+func (c *ResponseCache) key(ctx *gin.Context) string {
+	hash := sha256.New()
+	hash.Write([]byte(ctx.Request.URL.RequestURI()))
+	hash.Write([]byte{0})
+	hash.Write([]byte(i18n.Locale(ctx)))
+	hash.Write([]byte{0})
+	hash.Write([]byte(ctx.GetHeader("Accept")))
+	if user, err := GetUserFromContext(ctx); err == nil {
+		hash.Write([]byte{0})
+		hash.Write([]byte(user.ID.String()))
+	}
+	return "responses:" + hex.EncodeToString(hash.Sum(nil))
+}
+
+// ttl returns how long the response can be cached, it is not cached if the ttl is zero
+// The private responses are only cached for a single user
+func (c *ResponseCache) ttl(writer *capturingWriter, perUser bool) time.Duration {
+	if writer.Status() != http.StatusOK || len(writer.Header().Values("Set-Cookie")) > 0 || writer.Header().Get("Vary") == "*" {
+		return 0
+	}
+	directives := cacheControl(writer.Header().Get("Cache-Control"))
+	for _, name := range []string{"no-store", "no-cache"} {
+		if _, ok := directives[name]; ok {
+			return 0
+		}
+	}
+	_, public := directives["public"]
+	_, private := directives["private"]
+	if !(public && !private) && !(private && perUser) {
+		return 0
+	}
+	age, ok := directives["s-maxage"]
+	if !ok {
+		age = directives["max-age"]
+	}
+	seconds, err := strconv.Atoi(age)
+	if err != nil || seconds <= 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// send sends a cached response
+// The Age header tells the client how long the response has been cached
+func (c *ResponseCache) send(ctx *gin.Context, cached *cachedResponse) {
+	for name, values := range cached.Header {
+		ctx.Writer.Header().Del(name)
+		for _, value := range values {
+			ctx.Writer.Header().Add(name, value)
+		}
+	}
+	ctx.Header("Age", strconv.Itoa(int(time.Since(cached.StoredAt).Seconds())))
+	if etag := ctx.Writer.Header().Get("ETag"); etag != "" && api.NotModified(ctx, etag) {
+		return
+	}
+	ctx.Status(cached.Status)
+	_, _ = ctx.Writer.Write(cached.Body)
+}
+
+// cacheControl parses the directives of a Cache-Control header
+func cacheControl(header string) map[string]string {
+	directives := map[string]string{}
+	for _, directive := range strings.Split(header, ",") {
+		name, value, _ := strings.Cut(strings.TrimSpace(directive), "=")
+		if name != "" {
+			directives[strings.ToLower(name)] = strings.Trim(value, `"`)
+		}
+	}
+	return directives
+}
@@ -0,0 +1,176 @@
+package middlewares
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/go-api-template/go-backend/models"
+	api "github.com/go-api-template/go-backend/modules/utils/api"
+	"github.com/go-api-template/go-backend/services"
+	"github.com/google/uuid"
+)
+
+type Tenant struct {
+	organizationService services.OrganizationService
+	membershipService   services.MembershipService
+}
+
+var (
+	tenant          *Tenant
+	CtxOrganization = "current_organization"
+	CtxMembership   = "current_membership"
+
+	// HeaderOrganization is the header used to select the organization
+	// when the organization is not part of the path
+	HeaderOrganization = "X-Organization-ID"
+	// ParamOrganization is the path parameter used to select the organization
+	ParamOrganization = "organization_id"
+)
+
+// InitializeTenant initializes the tenant resolver
+func InitializeTenant(organizationService services.OrganizationService, membershipService services.MembershipService) {
+	tenant = &Tenant{organizationService, membershipService}
+}
+
+// organizationId extracts the organization id from the path or from the X-Organization-ID header
+// The path parameter takes precedence over the header
+func (t Tenant) organizationId(ctx *gin.Context) (uuid.UUID, error) {
+	id := ctx.Param(ParamOrganization)
+	if id == "" {
+		id = ctx.GetHeader(HeaderOrganization)
+	}
+	if id == "" {
+		return uuid.Nil, errors.New("no organization selected")
+	}
+	return uuid.Parse(id)
+}
+
+// organizationRole returns a middleware which loads the organization in the context
+// and checks that the current user has at least the given role in the organization.
+// Platform admins are allowed in every organization, even if they are not members.
+// It must be used after a middleware which adds the user to the context.
+func organizationRole(role models.OrganizationRole) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		// Get the user from the context
+		user, err := GetUserFromContext(ctx)
+		if err != nil {
+			api.Ctx(ctx).Unauthorized().
+				WithCode(api.CodeNotAuthenticated).
+				WithDescription("You are not logged in").
+				Send()
+			ctx.Abort()
+			return
+		}
+
+		// Get the organization id
+		organizationId, err := tenant.organizationId(ctx)
+		if err != nil {
+			api.Ctx(ctx).BadRequest().
+				WithCode(api.CodeInvalidOrganizationId).
+				WithDescription("Invalid organization id").
+				WithError(err).
+				Send()
+			ctx.Abort()
+			return
+		}
+
+		// Get the organization from the database
+		organization, err := tenant.organizationService.WithContext(ctx.Request.Context()).FindById(organizationId)
+		if err != nil {
+			api.Ctx(ctx).InternalServerError().WithError(err).Send()
+			ctx.Abort()
+			return
+		}
+		if organization == nil {
+			api.Ctx(ctx).NotFound().
+				WithCode(api.CodeOrganizationNotFound).
+				WithDescription("organization not found").
+				Send()
+			ctx.Abort()
+			return
+		}
+
+		// Get the membership of the user
+		membership, err := tenant.membershipService.WithContext(ctx.Request.Context()).FindByUser(organization.ID, user.ID)
+		if err != nil {
+			api.Ctx(ctx).InternalServerError().WithError(err).Send()
+			ctx.Abort()
+			return
+		}
+
+		// Check the role of the user in the organization
+		if !user.Role.IsPlatformAdmin() {
+			if membership == nil {
+				api.Ctx(ctx).Forbidden().
+					WithCode(api.CodeNotOrganizationMember).
+					WithDescription("You are not a member of this organization").
+					Send()
+				ctx.Abort()
+				return
+			}
+			if !membership.Role.Covers(role) {
+				api.Ctx(ctx).Forbidden().
+					WithCode(api.CodeOrganizationRoleRequired).
+					WithDescription("You are not allowed to perform this action in this organization").
+					Send()
+				ctx.Abort()
+				return
+			}
+		}
+
+		// The queries of the request are restricted to the organization
+		ctx.Request = ctx.Request.WithContext(models.WithOrganization(ctx.Request.Context(), organization.ID))
+		ctx.Set(CtxOrganization, organization)
+		if membership != nil {
+			ctx.Set(CtxMembership, membership)
+		}
+		ctx.Next()
+	}
+}
+
+// OrganizationMember checks if the user is a member of the selected organization
+func OrganizationMember() gin.HandlerFunc {
+	return organizationRole(models.OrganizationRoleMember)
+}
+
+// OrganizationAdmin checks if the user is an admin of the selected organization
+func OrganizationAdmin() gin.HandlerFunc {
+	return organizationRole(models.OrganizationRoleAdmin)
+}
+
+// OrganizationOwner checks if the user is an owner of the selected organization
+func OrganizationOwner() gin.HandlerFunc {
+	return organizationRole(models.OrganizationRoleOwner)
+}
+
+// GetOrganizationFromContext extracts the organization from the context
+func GetOrganizationFromContext(ctx *gin.Context) (*models.Organization, error) {
+	// Get the organization from the context
+	organization, ok := ctx.Get(CtxOrganization)
+	if !ok || organization == nil {
+		return nil, errors.New("organization not found in context")
+	}
+
+	// Cast to Organization model
+	if _, ok := organization.(*models.Organization); !ok {
+		return nil, errors.New("organization not found in context")
+	}
+
+	return organization.(*models.Organization), nil
+}
+
+// GetMembershipFromContext extracts the membership of the current user from the context
+// Platform admins which are not members of the organization have no membership
+func GetMembershipFromContext(ctx *gin.Context) (*models.Membership, error) {
+	// Get the membership from the context
+	membership, ok := ctx.Get(CtxMembership)
+	if !ok || membership == nil {
+		return nil, errors.New("membership not found in context")
+	}
+
+	// Cast to Membership model
+	if _, ok := membership.(*models.Membership); !ok {
+		return nil, errors.New("membership not found in context")
+	}
+
+	return membership.(*models.Membership), nil
+}
@@ -5,6 +5,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/go-api-template/go-backend/modules/config"
 	"github.com/go-api-template/go-backend/modules/middlewares"
+	api "github.com/go-api-template/go-backend/modules/utils/api"
 	"github.com/rs/zerolog/log"
 	"sync"
 )
@@ -43,14 +44,41 @@ func (r *router) initialize() {
 	// Create a new gin router
 	router := gin.New()
 
+	// Only trust the headers forwarding the ip address of the client when they are set by a trusted proxy,
+	// otherwise any client could choose its ip address and bypass the rate limits
+	var trustedProxies []string
+	if len(config.Config.Server.TrustedProxies) > 0 {
+		trustedProxies = config.Config.Server.TrustedProxies
+	}
+	if err := router.SetTrustedProxies(trustedProxies); err != nil {
+		log.Fatal().Err(err).Msg("Invalid trusted proxies")
+	}
+
+	// Report the invalid request bodies field by field
+	api.RegisterValidators()
+
 	// Add static files
 	router.StaticFile("/favicon.ico", "./assets/favicon.ico")
 	router.Use(static.Serve("/", static.LocalFile("./assets", false)))
 
 	// Add default middleware
+	// The metrics, the tracing, the request id and the access log come first, so they see the requests which panic
+	if config.Config.Metrics.Enable {
+		router.Use(middlewares.Metrics())
+	}
+	if config.Config.Tracing.Enable {
+		router.Use(middlewares.Tracing())
+	}
+	router.Use(middlewares.RequestId())
+	if config.Config.Logs.Access.Enable {
+		router.Use(middlewares.AccessLogger())
+	}
 	router.Use(gin.Recovery())
 	router.Use(middlewares.ConsoleLogger())
 	router.Use(middlewares.Cors())
+	router.Use(middlewares.Locale())
+	router.Use(middlewares.LimitBody(config.Config.Server.MaxBodyBytes))
+	router.Use(middlewares.IdempotencyKey())
 
 	// Set gin router
 	r.ginRouter = router
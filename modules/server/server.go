@@ -6,17 +6,26 @@ import (
 	"fmt"
 	"github.com/gin-gonic/gin"
 	"github.com/go-api-template/go-backend/controllers"
+	"github.com/go-api-template/go-backend/modules/cache"
 	"github.com/go-api-template/go-backend/modules/config"
+	"github.com/go-api-template/go-backend/modules/database/migrations"
 	"github.com/go-api-template/go-backend/modules/database/postgres"
 	redis_db "github.com/go-api-template/go-backend/modules/database/redis"
+	"github.com/go-api-template/go-backend/modules/health"
+	"github.com/go-api-template/go-backend/modules/metrics"
 	"github.com/go-api-template/go-backend/modules/middlewares"
 	"github.com/go-api-template/go-backend/modules/router"
+	"github.com/go-api-template/go-backend/modules/tracing"
 	"github.com/go-api-template/go-backend/routes"
 	"github.com/go-api-template/go-backend/services"
 	"github.com/go-redis/redis/v8"
 	"github.com/rs/zerolog/log"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 	"gorm.io/gorm"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
 	"time"
 )
 
@@ -24,6 +33,7 @@ import (
 // It is used to initialize the server
 type Server struct {
 	ctx         context.Context
+	tracer      *sdktrace.TracerProvider
 	gormDb      *gorm.DB
 	sqlDb       *sql.DB
 	redis       *redis.Client
@@ -42,7 +52,13 @@ func (s *Server) Run() (err error) {
 
 	// Initialize the server
 	s.ctx = context.TODO()
+	if err = s.trace(); err != nil {
+		return
+	}
 	s.gormDb, s.sqlDb = postgres_db.NewPostgres(s.ctx)
+	if err = s.migrate(); err != nil {
+		return
+	}
 	s.redis = redis_db.NewRedis(s.ctx)
 	s.router = router.NewRouter()
 	s.services = services.NewServices(s.ctx, s.gormDb, s.sqlDb, s.redis)
@@ -51,34 +67,180 @@ func (s *Server) Run() (err error) {
 
 	// Initialize the middlewares
 	middlewares.InitializeAuthorizer(s.services.UserService)
-
-	//
-	defer func(sqlDb *sql.DB) { _ = sqlDb.Close() }(s.sqlDb)
-	defer func(redis *redis.Client) { _ = redis.Close() }(s.redis)
+	middlewares.InitializeTenant(s.services.OrganizationService, s.services.MembershipService)
+	middlewares.InitializeIdempotency(s.redis)
+	middlewares.InitializeRateLimiter(s.redis)
+	middlewares.InitializeResponseCache(cache.NewCache(s.redis))
 
 	// Server configuration
 	server := &http.Server{
 		Addr:              fmt.Sprintf(":%s", c.Server.Port),
 		Handler:           s.router,
-		ReadTimeout:       1 * time.Second,
-		WriteTimeout:      1 * time.Second,
-		IdleTimeout:       30 * time.Second,
-		ReadHeaderTimeout: 2 * time.Second,
+		ReadTimeout:       time.Duration(c.Server.ReadTimeout) * time.Second,
+		WriteTimeout:      time.Duration(c.Server.WriteTimeout) * time.Second,
+		IdleTimeout:       time.Duration(c.Server.IdleTimeout) * time.Second,
+		ReadHeaderTimeout: time.Duration(c.Server.ReadHeaderTimeout) * time.Second,
+		MaxHeaderBytes:    c.Server.MaxHeaderBytes,
+	}
+
+	// Serve the requests over TLS if a certificate is given
+	if c.Server.TLS.Enable {
+		tlsConfig, certs, err := newTLSConfig()
+		if err != nil {
+			log.Error().Err(err).Msg("Configuring TLS")
+			s.close()
+			return err
+		}
+		server.TLSConfig = tlsConfig
+		defer certs.reloadOnSignal()()
+	}
+
+	// Serve the metrics on the admin port, so they are not exposed to the clients
+	var metricsServer *http.Server
+	if c.Metrics.Enable {
+		metricsServer = &http.Server{
+			Addr:              fmt.Sprintf(":%s", c.Metrics.Port),
+			Handler:           metrics.Handler(),
+			ReadHeaderTimeout: time.Duration(c.Server.ReadHeaderTimeout) * time.Second,
+		}
 	}
 
 	log.Info().
 		Str("scheme", c.Server.Scheme).
 		Str("host", c.Server.Host).
 		Str("port", c.Server.Port).
+		Bool("tls", c.Server.TLS.Enable).
 		Str("base", c.Server.BasePath).
+		Str("metrics_port", c.Metrics.Port).
 		Str("version", c.App.Version).
 		Msgf("Starting %s Server", c.App.Name)
 
-	if err = server.ListenAndServe(); err != nil {
-		log.Fatal().
-			Err(err).
-			Msgf("%s Server Closed", c.App.Name)
+	// Serve the requests until a signal stops the server
+	signals, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	serverErr := make(chan error, 2)
+	go func() {
+		if server.TLSConfig != nil {
+			serverErr <- server.ListenAndServeTLS("", "")
+			return
+		}
+		serverErr <- server.ListenAndServe()
+	}()
+	if metricsServer != nil {
+		go func() {
+			serverErr <- fmt.Errorf("serving the metrics: %w", metricsServer.ListenAndServe())
+		}()
 	}
+	select {
+	case err = <-serverErr:
+		log.Error().Err(err).Msgf("%s Server Closed", c.App.Name)
+	case <-signals.Done():
+		log.Info().Msgf("Stopping %s Server...", c.App.Name)
+	}
+
+	// A second signal kills the server without waiting
+	stop()
 
+	// Give a grace period to the in-flight requests and to the queued mails
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(c.Server.ShutdownTimeout)*time.Second)
+	defer cancel()
+	s.shutdown(ctx, server, metricsServer)
+
+	log.Info().Msgf("%s Server Stopped", c.App.Name)
 	return
 }
+
+// shutdown stops the server gracefully
+// The workers are stopped in order: the server stops accepting requests and waits for the in-flight ones,
+// then the mailer sends the mails they have queued and the last spans are sent, and the connections are closed last.
+// The metrics are served until the in-flight requests are done
+func (s *Server) shutdown(ctx context.Context, server *http.Server, metricsServer *http.Server) {
+	if err := server.Shutdown(ctx); err != nil {
+		log.Error().Err(err).Msg("Waiting for the in-flight requests")
+	}
+	if metricsServer != nil {
+		if err := metricsServer.Shutdown(ctx); err != nil {
+			log.Error().Err(err).Msg("Stopping the metrics server")
+		}
+	}
+	if s.services.MailService != nil {
+		if err := s.services.MailService.Stop(ctx); err != nil {
+			log.Error().Err(err).Msg("Sending the queued mails")
+		}
+	}
+	if s.tracer != nil {
+		if err := s.tracer.Shutdown(ctx); err != nil {
+			log.Error().Err(err).Msg("Sending the last spans")
+		}
+	}
+	s.close()
+}
+
+// trace sends the traces to the exporter of the configuration
+// It is done first, so the connections to the databases are traced
+func (s *Server) trace() error {
+	if !config.Config.Tracing.Enable {
+		return nil
+	}
+	exporter, err := tracing.NewExporter(s.ctx)
+	if err != nil {
+		log.Error().Err(err).Msg("Creating the tracing exporter")
+		return err
+	}
+	s.tracer = tracing.NewTracerProvider(exporter)
+	return nil
+}
+
+// close closes the connections to Redis and to the database
+func (s *Server) close() {
+	if err := s.redis.Close(); err != nil {
+		log.Error().Err(err).Msg("Closing the Redis connection")
+	}
+	if err := s.sqlDb.Close(); err != nil {
+		log.Error().Err(err).Msg("Closing the database connection")
+	}
+}
+
+// migrate applies or checks the pending migrations
+// depending on the configuration of the database
+func (s *Server) migrate() error {
+	c := config.Config.Database.Migrations
+	migrator := migrations.NewMigrator(s.ctx, s.sqlDb)
+
+	// Apply the pending migrations
+	if c.Apply {
+		if _, err := migrator.Up(0); err != nil {
+			log.Error().Err(err).Msg("Applying migrations")
+			return err
+		}
+	}
+
+	// Check the pending migrations in the readiness probe
+	health.Register(health.Check{
+		Name: "migrations",
+		Checker: func(ctx context.Context) error {
+			pending, err := migrator.PendingContext(ctx)
+			if err == nil && len(pending) > 0 {
+				err = fmt.Errorf("%d migrations are pending", len(pending))
+			}
+			return err
+		},
+	})
+
+	// Check the pending migrations
+	pending, err := migrator.Pending()
+	if err != nil {
+		log.Error().Err(err).Msg("Checking migrations")
+		return err
+	}
+	if len(pending) > 0 {
+		if c.FailOnPending {
+			err = fmt.Errorf("%d migrations are pending, run the migrate up command", len(pending))
+			log.Error().Err(err).Msg("Checking migrations")
+			return err
+		}
+		log.Warn().Int("pending", len(pending)).Msg("Some migrations are pending")
+	}
+
+	return nil
+}
@@ -0,0 +1,141 @@
+package server
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"github.com/go-api-template/go-backend/modules/config"
+	"github.com/rs/zerolog/log"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+)
+
+// tlsVersions are the versions of TLS which can be configured
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// clientAuthTypes are the policies of the authentication of the clients which can be configured
+var clientAuthTypes = map[string]tls.ClientAuthType{
+	"none":               tls.NoClientCert,
+	"request":            tls.RequestClientCert,
+	"require":            tls.RequireAnyClientCert,
+	"verify-if-given":    tls.VerifyClientCertIfGiven,
+	"require-and-verify": tls.RequireAndVerifyClientCert,
+}
+
+// certificates holds the certificate of the server and the authorities of the client certificates
+// They are read from the files of the configuration and reloaded on SIGHUP,
+// so a renewed certificate is used without restarting the server
+type certificates struct {
+	mutex       sync.RWMutex
+	config      *tls.Config
+	certificate *tls.Certificate
+	clientCAs   *x509.CertPool
+}
+
+// newTLSConfig creates the TLS configuration of the server
+func newTLSConfig() (*tls.Config, *certificates, error) {
+	c := config.Config.Server.TLS
+	tlsConfig := &tls.Config{
+		MinVersion: tlsVersions[c.MinVersion],
+		ClientAuth: clientAuthTypes[c.ClientAuth],
+		NextProtos: []string{"h2", "http/1.1"},
+	}
+
+	// Cipher suites of TLS 1.0 to 1.2, the cipher suites of TLS 1.3 are not configurable
+	if len(c.CipherSuites) > 0 {
+		ids := map[string]uint16{}
+		for _, suite := range tls.CipherSuites() {
+			ids[suite.Name] = suite.ID
+		}
+		for _, name := range c.CipherSuites {
+			id, ok := ids[name]
+			if !ok {
+				return nil, nil, fmt.Errorf("unknown or insecure TLS cipher suite %q", name)
+			}
+			tlsConfig.CipherSuites = append(tlsConfig.CipherSuites, id)
+		}
+	}
+
+	// Load the certificates
+	certs := &certificates{config: tlsConfig}
+	if err := certs.load(); err != nil {
+		return nil, nil, err
+	}
+	tlsConfig.GetCertificate = certs.getCertificate
+	tlsConfig.GetConfigForClient = certs.configForClient
+
+	return tlsConfig, certs, nil
+}
+
+// load reads the certificates from their files
+// The current certificates are kept if one of the files cannot be read
+func (c *certificates) load() error {
+	conf := config.Config.Server.TLS
+	certificate, err := tls.LoadX509KeyPair(conf.CertFile, conf.KeyFile)
+	if err != nil {
+		return fmt.Errorf("loading the TLS certificate: %w", err)
+	}
+
+	var clientCAs *x509.CertPool
+	if conf.ClientCAFile != "" {
+		pem, err := os.ReadFile(conf.ClientCAFile)
+		if err != nil {
+			return fmt.Errorf("loading the TLS client authorities: %w", err)
+		}
+		clientCAs = x509.NewCertPool()
+		if !clientCAs.AppendCertsFromPEM(pem) {
+			return errors.New("loading the TLS client authorities: no certificate found")
+		}
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.certificate = &certificate
+	c.clientCAs = clientCAs
+	return nil
+}
+
+// getCertificate returns the current certificate of the server
+func (c *certificates) getCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.certificate, nil
+}
+
+// configForClient returns the TLS configuration of a new connection with the current client authorities
+func (c *certificates) configForClient(*tls.ClientHelloInfo) (*tls.Config, error) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	tlsConfig := c.config.Clone()
+	tlsConfig.GetConfigForClient = nil
+	tlsConfig.ClientCAs = c.clientCAs
+	return tlsConfig, nil
+}
+
+// reloadOnSignal reloads the certificates each time the server receives a SIGHUP signal
+// The returned function stops listening to the signal
+func (c *certificates) reloadOnSignal() func() {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP)
+	go func() {
+		for range signals {
+			if err := c.load(); err != nil {
+				log.Error().Err(err).Msg("Reloading the TLS certificates")
+				continue
+			}
+			log.Info().Msg("TLS certificates reloaded")
+		}
+	}()
+	return func() {
+		signal.Stop(signals)
+		close(signals)
+	}
+}
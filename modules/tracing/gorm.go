@@ -0,0 +1,83 @@
+package tracing
+
+import (
+	"errors"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+// spanKey is the key of the span of a query in the gorm statement
+const spanKey = "tracing:span"
+
+// GormPlugin creates a span for each query of gorm, it is added with gormDb.Use
+// The spans are children of the span in the context of the query, see gormDb.WithContext
+type GormPlugin struct{}
+
+// Name returns the name of the plugin
+func (GormPlugin) Name() string {
+	return "tracing"
+}
+
+// Initialize registers the callbacks which create the spans
+func (p GormPlugin) Initialize(db *gorm.DB) error {
+	callback := db.Callback()
+	return errors.Join(
+		callback.Create().Before("*").Register("tracing:before_create", p.before("create")),
+		callback.Create().After("*").Register("tracing:after_create", p.after),
+		callback.Query().Before("*").Register("tracing:before_query", p.before("query")),
+		callback.Query().After("*").Register("tracing:after_query", p.after),
+		callback.Update().Before("*").Register("tracing:before_update", p.before("update")),
+		callback.Update().After("*").Register("tracing:after_update", p.after),
+		callback.Delete().Before("*").Register("tracing:before_delete", p.before("delete")),
+		callback.Delete().After("*").Register("tracing:after_delete", p.after),
+		callback.Row().Before("*").Register("tracing:before_row", p.before("row")),
+		callback.Row().After("*").Register("tracing:after_row", p.after),
+		callback.Raw().Before("*").Register("tracing:before_raw", p.before("raw")),
+		callback.Raw().After("*").Register("tracing:after_raw", p.after),
+	)
+}
+
+// before starts the span of the query
+func (GormPlugin) before(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		name := operation
+		if db.Statement.Table != "" {
+			name += " " + db.Statement.Table
+		}
+		ctx, span := Tracer().Start(db.Statement.Context, name,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(
+				semconv.DBSystemPostgreSQL,
+				semconv.DBOperation(operation),
+				semconv.DBSQLTable(db.Statement.Table),
+			),
+		)
+		db.Statement.Context = ctx
+		db.InstanceSet(spanKey, span)
+	}
+}
+
+// after ends the span of the query
+// The statement is recorded with its placeholders, the values are never recorded.
+// A record which is not found is not an error
+func (GormPlugin) after(db *gorm.DB) {
+	value, ok := db.InstanceGet(spanKey)
+	if !ok {
+		return
+	}
+	span, ok := value.(trace.Span)
+	if !ok {
+		return
+	}
+	span.SetAttributes(
+		semconv.DBStatement(db.Statement.SQL.String()),
+		attribute.Int64("db.rows_affected", db.Statement.RowsAffected),
+	)
+	err := db.Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		err = nil
+	}
+	End(span, err)
+}
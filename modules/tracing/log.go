@@ -0,0 +1,20 @@
+package tracing
+
+import (
+	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// LogHook adds the ids of the trace and of the span to the log events, it is added with logger.Hook
+// The ids are taken from the context of the event or of its logger, see Event.Ctx and Context.Ctx
+type LogHook struct{}
+
+func (LogHook) Run(e *zerolog.Event, _ zerolog.Level, _ string) {
+	ctx := e.GetCtx()
+	if ctx == nil {
+		return
+	}
+	if span := trace.SpanContextFromContext(ctx); span.IsValid() {
+		e.Str("trace_id", span.TraceID().String()).Str("span_id", span.SpanID().String())
+	}
+}
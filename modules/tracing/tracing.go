@@ -0,0 +1,76 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"github.com/go-api-template/go-backend/modules/config"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
+	"os"
+)
+
+// instrumentationName is the name of the tracer of the server
+const instrumentationName = "github.com/go-api-template/go-backend"
+
+// Tracer returns the tracer of the server
+// The spans are dropped until a tracer provider is set by NewTracerProvider
+func Tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
+
+// NewExporter creates the exporter of the spans chosen in the configuration
+func NewExporter(ctx context.Context) (sdktrace.SpanExporter, error) {
+	c := config.Config.Tracing
+	switch c.Exporter {
+	case "stdout":
+		return stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+	case "otlp":
+		// The endpoint can also be set by the standard OTEL_EXPORTER_OTLP_* variables
+		var options []otlptracehttp.Option
+		if c.Endpoint != "" {
+			options = append(options, otlptracehttp.WithEndpoint(c.Endpoint))
+		}
+		if c.Insecure {
+			options = append(options, otlptracehttp.WithInsecure())
+		}
+		return otlptracehttp.New(ctx, options...)
+	default:
+		return nil, fmt.Errorf("unknown tracing exporter %q", c.Exporter)
+	}
+}
+
+// NewTracerProvider creates the tracer provider which sends the spans to the exporter, and sets it as the global one
+// The W3C trace context and baggage are propagated with the requests.
+// The tracer provider must be shut down to send the last spans
+func NewTracerProvider(exporter sdktrace.SpanExporter) *sdktrace.TracerProvider {
+	c := config.Config
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(c.Tracing.SampleRatio))),
+		sdktrace.WithResource(resource.NewWithAttributes(
+			semconv.SchemaURL,
+			semconv.ServiceName(c.App.Name),
+			semconv.ServiceVersion(c.App.Version),
+			semconv.DeploymentEnvironment(string(c.App.Environnement)),
+		)),
+	)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	return provider
+}
+
+// End ends a span, and records the error if there is one
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,183 @@
+package api
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql/driver"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"github.com/go-api-template/go-backend/modules/config"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// Cursor is the position of the last item of a page in a keyset paginated list
+// It holds the values of the sorted columns and of the primary key of the item
+// It is sent to the clients as an opaque and signed string
+type Cursor struct {
+	// Sort identifies the sort of the list, a cursor cannot be used with another sort
+	Sort string `json:"s"`
+	// Values are the values of the sorted columns followed by the primary key
+	Values []any `json:"v"`
+}
+
+// ErrInvalidCursor is returned when a cursor is malformed, forged, or used with another sort
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// schemas caches the parsed models used to read the values of the cursors
+var schemas = &sync.Map{}
+
+// Encode signs the cursor and encodes it as an url safe string
+func (c Cursor) Encode() (string, error) {
+	payload, err := json.Marshal(c)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(payload) + "." +
+		base64.RawURLEncoding.EncodeToString(cursorSignature(payload)), nil
+}
+
+// DecodeCursor verifies the signature of an encoded cursor and decodes it
+func DecodeCursor(encoded string) (*Cursor, error) {
+	data, signature, ok := strings.Cut(encoded, ".")
+	if !ok {
+		return nil, ErrInvalidCursor
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(data)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	mac, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil || !hmac.Equal(mac, cursorSignature(payload)) {
+		return nil, ErrInvalidCursor
+	}
+
+	cursor := &Cursor{}
+	if err := json.Unmarshal(payload, cursor); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	return cursor, nil
+}
+
+// cursorSignature signs the payload of a cursor
+func cursorSignature(payload []byte) []byte {
+	mac := hmac.New(sha256.New, []byte(config.Config.Pagination.CursorSecret))
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
+
+// sortKey identifies a sort, it is stored in the cursors
+func sortKey(sorts []Sort) string {
+	keys := make([]string, len(sorts))
+	for i, s := range sorts {
+		keys[i] = s.Column
+		if s.Desc {
+			keys[i] = "-" + s.Column
+		}
+	}
+	return strings.Join(keys, ",")
+}
+
+// keyset applies the keyset pagination to the query
+// The rows are sorted by the sorted columns then by the primary key, which makes the order total,
+// and only the rows after the cursor are selected. One more row than the limit is selected
+// to know if there is a next page.
+func (f Filter) keyset(query *gorm.DB) *gorm.DB {
+	// The model is not assigned yet when the scopes are executed
+	model := query.Statement.Model
+	if model == nil {
+		model = query.Statement.Dest
+	}
+	if err := query.Statement.Parse(model); err != nil {
+		_ = query.AddError(err)
+		return query
+	}
+	primaryField := query.Statement.Schema.PrioritizedPrimaryField
+	if primaryField == nil {
+		_ = query.AddError(errors.New("keyset pagination requires a primary key"))
+		return query
+	}
+
+	// Sort by the primary key last, in the same direction as the last sorted column
+	sorts := append(append([]Sort{}, f.Sort...), Sort{Column: query.Statement.Table + "." + primaryField.DBName})
+	if len(f.Sort) > 0 {
+		sorts[len(sorts)-1].Desc = f.Sort[len(f.Sort)-1].Desc
+	}
+
+	// Select the rows after the cursor:
+	// (c1 > v1) OR (c1 = v1 AND c2 > v2) OR (c1 = v1 AND c2 = v2 AND c3 > v3) ...
+	if f.After != nil && len(f.After.Values) == len(sorts) {
+		var clauses []string
+		var values []any
+		for i, s := range sorts {
+			var parts []string
+			for j := 0; j < i; j++ {
+				parts = append(parts, sorts[j].Column+" = ?")
+				values = append(values, f.After.Values[j])
+			}
+			operator := " > ?"
+			if s.Desc {
+				operator = " < ?"
+			}
+			parts = append(parts, s.Column+operator)
+			values = append(values, f.After.Values[i])
+			clauses = append(clauses, "("+strings.Join(parts, " AND ")+")")
+		}
+		query = query.Where(strings.Join(clauses, " OR "), values...)
+	}
+
+	for _, s := range sorts {
+		query = query.Order(s.orderBy())
+	}
+	if f.Limit != nil {
+		query = query.Limit(*f.Limit + 1)
+	}
+	return query
+}
+
+// nextCursor returns the cursor pointing after the item
+func (f Filter) nextCursor(item any) (string, error) {
+	s, err := schema.Parse(item, schemas, schema.NamingStrategy{})
+	if err != nil {
+		return "", err
+	}
+	if s.PrioritizedPrimaryField == nil {
+		return "", errors.New("keyset pagination requires a primary key")
+	}
+
+	// Read the values of the sorted columns and of the primary key
+	value := reflect.Indirect(reflect.ValueOf(item))
+	cursor := Cursor{Sort: sortKey(f.Sort)}
+	for _, sorted := range f.Sort {
+		field := s.LookUpField(sorted.Column[strings.LastIndex(sorted.Column, ".")+1:])
+		if field == nil {
+			return "", errors.New("unknown sorted column " + sorted.Column)
+		}
+		v, err := cursorValue(field, value)
+		if err != nil {
+			return "", err
+		}
+		cursor.Values = append(cursor.Values, v)
+	}
+	id, err := cursorValue(s.PrioritizedPrimaryField, value)
+	if err != nil {
+		return "", err
+	}
+	cursor.Values = append(cursor.Values, id)
+
+	return cursor.Encode()
+}
+
+// cursorValue reads the value of a field as it is stored in the database
+func cursorValue(field *schema.Field, value reflect.Value) (any, error) {
+	v, _ := field.ValueOf(context.Background(), value)
+	if valuer, ok := v.(driver.Valuer); ok {
+		return valuer.Value()
+	}
+	return v, nil
+}
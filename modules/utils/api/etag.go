@@ -0,0 +1,84 @@
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// NewETag returns the entity tag of a version of a resource
+// It changes each time the resource is updated
+func NewETag(id string, updatedAt time.Time) string {
+	hash := sha256.Sum256([]byte(id + "@" + updatedAt.UTC().Format(time.RFC3339Nano)))
+	return `"` + hex.EncodeToString(hash[:16]) + `"`
+}
+
+// NewWeakETag returns the weak entity tag of a representation
+// It only changes when the content of the representation changes
+func NewWeakETag(content []byte) string {
+	hash := sha256.Sum256(content)
+	return `W/"` + hex.EncodeToString(hash[:16]) + `"`
+}
+
+// NotModified sets the ETag header of the response
+// The 304 response is sent if the client already has this version of the resource,
+// given in the If-None-Match header, the caller must then stop handling the request
+func NotModified(ctx *gin.Context, etag string) bool {
+	if etag == "" {
+		return false
+	}
+	ctx.Header("ETag", etag)
+	if !matchETag(ctx.GetHeader("If-None-Match"), etag, false) {
+		return false
+	}
+	ctx.Status(http.StatusNotModified)
+	return true
+}
+
+// IfMatch checks that the client modifies the current version of the resource
+// The version must be given in the If-Match header, so two clients cannot overwrite each other.
+// The error response is sent if the header is missing or if the resource has been modified,
+// the caller must then stop handling the request
+func IfMatch(ctx *gin.Context, etag string) bool {
+	header := ctx.GetHeader("If-Match")
+	if header == "" {
+		Ctx(ctx).PreconditionRequired().
+			WithCode(CodePreconditionRequired).
+			WithDescription("The If-Match header is required to modify this resource").
+			Send()
+		return false
+	}
+	if !matchETag(header, etag, true) {
+		ctx.Header("ETag", etag)
+		Ctx(ctx).PreconditionFailed().
+			WithCode(CodeResourceModified).
+			WithDescription("The resource has been modified since it was read").
+			Send()
+		return false
+	}
+	return true
+}
+
+// matchETag returns true if the entity tag is in the list of a conditional header
+// The strong comparison is used by If-Match, the weak one by If-None-Match
+func matchETag(header string, etag string, strong bool) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" {
+			return true
+		}
+		if strong {
+			if candidate == etag && !strings.HasPrefix(candidate, "W/") {
+				return true
+			}
+			continue
+		}
+		if strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
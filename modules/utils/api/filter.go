@@ -1,82 +1,370 @@
 package api
 
 import (
+	"errors"
+	"fmt"
 	"github.com/gin-gonic/gin"
 	"github.com/go-mods/convert"
+	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"time"
 )
 
+// Operator is a comparison operator of the filter language
+// It is given in the query as filter[field][operator]=value
+type Operator string
+
+// Enum of the operators
+const (
+	OperatorEq    Operator = "eq"
+	OperatorNe    Operator = "ne"
+	OperatorLt    Operator = "lt"
+	OperatorLte   Operator = "lte"
+	OperatorGt    Operator = "gt"
+	OperatorGte   Operator = "gte"
+	OperatorLike  Operator = "like"
+	OperatorIlike Operator = "ilike"
+	OperatorIn    Operator = "in"
+	OperatorNull  Operator = "null"
+)
+
+// Sets of operators commonly allowed on a type of field
+var (
+	// EqualityOperators can be used on any field
+	EqualityOperators = []Operator{OperatorEq, OperatorNe, OperatorIn}
+	// StringOperators can be used on text fields
+	StringOperators = []Operator{OperatorEq, OperatorNe, OperatorIn, OperatorLike, OperatorIlike}
+	// RangeOperators can be used on numbers and dates
+	RangeOperators = []Operator{OperatorEq, OperatorNe, OperatorLt, OperatorLte, OperatorGt, OperatorGte}
+	// NullOperators can be used on nullable fields
+	NullOperators = []Operator{OperatorNull}
+)
+
+// Field describes how a field of a resource can be used in a list query
+type Field struct {
+	// Column is the database column, it should be prefixed with its table
+	Column string
+	// Operators are the operators allowed to filter on the field
+	// The field cannot be filtered if it is empty
+	Operators []Operator
+	// Sortable is true if the list can be sorted by the field
+	Sortable bool
+	// Parse converts a value of the query to the type of the column
+	// The value is bound as a string if it is nil
+	Parse func(value string) (any, error)
+}
+
+// ParseTime parses a date, or a date and a time in the RFC 3339 format
+func ParseTime(value string) (any, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		return nil, errors.New("must be a date or a date and a time in the RFC 3339 format")
+	}
+	return t, nil
+}
+
+// ParseBool parses a boolean
+func ParseBool(value string) (any, error) {
+	b, err := convert.ToBool(value)
+	if err != nil {
+		return nil, errors.New("must be true or false")
+	}
+	return b, nil
+}
+
+// ParseUUID parses a uuid
+func ParseUUID(value string) (any, error) {
+	id, err := uuid.Parse(value)
+	if err != nil {
+		return nil, errors.New("must be a uuid")
+	}
+	return id, nil
+}
+
+// Fields maps the name of a field in the query to its description
+// Each resource declares the fields which can be filtered and sorted
+type Fields map[string]Field
+
+// Condition is a validated filter on a column
+type Condition struct {
+	Column   string
+	Operator Operator
+	Value    any
+}
+
+// Sort is a validated sort on a column
+type Sort struct {
+	Column string
+	Desc   bool
+}
+
+// Limits of the number of items in a page
+const (
+	// DefaultLimit is used when no limit is given
+	DefaultLimit = 20
+	// MaxLimit is the highest limit which can be given
+	MaxLimit = 100
+)
+
+// Filter holds the pagination, the conditions and the sort of a list query
 type Filter struct {
-	Page    *int
-	Limit   *int
-	Offset  *int
-	SortBy  []string
-	OrderBy []string
-	Search  string
+	Page       *int
+	Limit      *int
+	Offset     *int
+	Conditions []Condition
+	Sort       []Sort
+	// Keyset is true when the list is paginated with cursors instead of pages
+	Keyset bool
+	// After is the cursor of the last item of the previous page, it is nil on the first page
+	After *Cursor
 }
 
+// filterParam matches the filter query parameters: filter[field] or filter[field][operator]
+var filterParam = regexp.MustCompile(`^filter\[([^\[\]]+)\](?:\[([^\[\]]+)\])?$`)
+
 // GetFilter creates a filter from the query parameters
-func GetFilter(c *gin.Context) Filter {
+// The filtered and sorted fields must be declared in fields,
+// otherwise an error listing the allowed fields is returned
+func GetFilter(c *gin.Context, fields Fields) (Filter, error) {
 	var filter Filter
 
-	// Get the page number
+	// Get the page number, the first page is 1
+	filter.Page = convert.ToPtr(1)
 	if page, ok := c.GetQuery("page"); ok {
-		if page, err := convert.ToInt(page); err == nil {
+		if page, err := convert.ToInt(page); err == nil && page > 1 {
 			filter.Page = convert.ToPtr(page)
 		}
 	}
 
-	// Get the limit
+	// Get the limit, bounded to keep the pages small
+	filter.Limit = convert.ToPtr(DefaultLimit)
 	if limit, ok := c.GetQuery("limit"); ok {
-		if limit, err := convert.ToInt(limit); err == nil {
-			filter.Limit = convert.ToPtr(limit)
+		if limit, err := convert.ToInt(limit); err == nil && limit > 0 {
+			filter.Limit = convert.ToPtr(min(limit, MaxLimit))
 		}
 	}
 
 	// Get the offset
-	if filter.Page != nil && *filter.Page > 1 && filter.Limit != nil {
-		page := *filter.Page
-		limit := *filter.Limit
-		filter.Offset = convert.ToPtr((page - 1) * limit)
+	filter.Offset = convert.ToPtr((*filter.Page - 1) * *filter.Limit)
+
+	// Get the conditions, sorted to always build the same query
+	query := c.Request.URL.Query()
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		parts := filterParam.FindStringSubmatch(key)
+		if parts == nil {
+			if key == "filter" || strings.HasPrefix(key, "filter[") {
+				return filter, fmt.Errorf("invalid filter %s, the syntax is filter[field][operator]=value", key)
+			}
+			continue
+		}
+		for _, value := range query[key] {
+			condition, err := fields.condition(parts[1], Operator(parts[2]), value)
+			if err != nil {
+				return filter, err
+			}
+			filter.Conditions = append(filter.Conditions, condition)
+		}
 	}
 
-	// Get the sort by
-	if sortBy, ok := c.GetQueryArray("sort_by"); ok {
-		filter.SortBy = sortBy
+	// Get the sort, a field is sorted in descending order when prefixed with a minus sign
+	for _, values := range c.QueryArray("sort") {
+		for _, name := range strings.Split(values, ",") {
+			name = strings.TrimSpace(name)
+			if name == "" {
+				continue
+			}
+			s, err := fields.sort(name)
+			if err != nil {
+				return filter, err
+			}
+			filter.Sort = append(filter.Sort, s)
+		}
 	}
 
-	// Get the order by
-	if orderBy, ok := c.GetQueryArray("order_by"); ok {
-		filter.OrderBy = orderBy
+	// Get the cursor, the list is paginated with cursors when it is given, even empty
+	if cursor, ok := c.GetQuery("cursor"); ok {
+		filter.Keyset = true
+		filter.Page = nil
+		filter.Offset = nil
+		if cursor != "" {
+			after, err := DecodeCursor(cursor)
+			if err != nil || after.Sort != sortKey(filter.Sort) {
+				return filter, ErrInvalidCursor
+			}
+			filter.After = after
+		}
 	}
 
-	// Get the search
-	if search, ok := c.GetQuery("search"); ok {
-		filter.Search = search
+	return filter, nil
+}
+
+// condition validates a filter on a field
+func (f Fields) condition(name string, operator Operator, value string) (Condition, error) {
+	field, ok := f[name]
+	if !ok || len(field.Operators) == 0 {
+		return Condition{}, fmt.Errorf("unknown filter field %s, the allowed fields are: %s", name, f.names(false))
+	}
+	if operator == "" {
+		operator = OperatorEq
+	}
+	if !slices.Contains(field.Operators, operator) {
+		return Condition{}, fmt.Errorf("unknown operator %s for the filter field %s, the allowed operators are: %s",
+			operator, name, joinOperators(field.Operators))
+	}
+
+	condition := Condition{Column: field.Column, Operator: operator, Value: value}
+	switch operator {
+	case OperatorIn:
+		values := strings.Split(value, ",")
+		parsed := make([]any, len(values))
+		for i, v := range values {
+			p, err := field.parse(name, operator, v)
+			if err != nil {
+				return Condition{}, err
+			}
+			parsed[i] = p
+		}
+		condition.Value = parsed
+	case OperatorLike, OperatorIlike:
+		// The patterns are always strings
+	case OperatorNull:
+		isNull, err := convert.ToBool(value)
+		if err != nil {
+			return Condition{}, fmt.Errorf("the value of the filter %s[%s] must be true or false", name, operator)
+		}
+		condition.Value = isNull
+	default:
+		parsed, err := field.parse(name, operator, value)
+		if err != nil {
+			return Condition{}, err
+		}
+		condition.Value = parsed
 	}
 
-	return filter
+	return condition, nil
+}
+
+// parse converts a value of the filter to the type of the field
+func (f Field) parse(name string, operator Operator, value string) (any, error) {
+	if f.Parse == nil {
+		return value, nil
+	}
+	parsed, err := f.Parse(value)
+	if err != nil {
+		return nil, fmt.Errorf("the value %q of the filter %s[%s] %w", value, name, operator, err)
+	}
+	return parsed, nil
+}
+
+// sort validates a sort on a field
+func (f Fields) sort(name string) (Sort, error) {
+	s := Sort{}
+	if strings.HasPrefix(name, "-") {
+		s.Desc = true
+		name = strings.TrimPrefix(name, "-")
+	}
+
+	field, ok := f[name]
+	if !ok || !field.Sortable {
+		return s, fmt.Errorf("unknown sort field %s, the allowed fields are: %s", name, f.names(true))
+	}
+	s.Column = field.Column
+
+	return s, nil
+}
+
+// names returns the sorted names of the filterable or sortable fields
+func (f Fields) names(sortable bool) string {
+	var names []string
+	for name, field := range f {
+		if (sortable && field.Sortable) || (!sortable && len(field.Operators) > 0) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+// joinOperators returns the operators separated by a comma
+func joinOperators(operators []Operator) string {
+	names := make([]string, len(operators))
+	for i, operator := range operators {
+		names[i] = string(operator)
+	}
+	return strings.Join(names, ", ")
 }
 
 // Apply filter to the query
+// The columns come from the declared fields and the values are always bound as parameters
 func (f Filter) Apply(query *gorm.DB) *gorm.DB {
+	query = f.Where(query)
+	if f.Keyset {
+		return f.keyset(query)
+	}
 	if f.Limit != nil {
 		query = query.Limit(*f.Limit)
 	}
 	if f.Offset != nil {
 		query = query.Offset(*f.Offset)
 	}
-	if len(f.SortBy) > 0 {
-		for i, sortBy := range f.SortBy {
-			if len(f.OrderBy) > i {
-				query = query.Order(sortBy + " " + f.OrderBy[i])
-			} else {
-				query = query.Order(sortBy)
-			}
-		}
+	for _, s := range f.Sort {
+		query = query.Order(s.orderBy())
 	}
-	if f.Search != "" {
-		query = query.Where(f.Search)
+	return query
+}
+
+// orderBy returns the order clause of the sort
+func (s Sort) orderBy() clause.OrderByColumn {
+	return clause.OrderByColumn{Column: clause.Column{Name: s.Column, Raw: true}, Desc: s.Desc}
+}
+
+// Where applies the conditions of the filter to the query, without the pagination and the sort
+// It is used to count all the items matching the filter
+func (f Filter) Where(query *gorm.DB) *gorm.DB {
+	for _, condition := range f.Conditions {
+		query = condition.Apply(query)
 	}
 	return query
 }
+
+// Apply the condition to the query
+func (c Condition) Apply(query *gorm.DB) *gorm.DB {
+	switch c.Operator {
+	case OperatorNe:
+		return query.Where(c.Column+" <> ?", c.Value)
+	case OperatorLt:
+		return query.Where(c.Column+" < ?", c.Value)
+	case OperatorLte:
+		return query.Where(c.Column+" <= ?", c.Value)
+	case OperatorGt:
+		return query.Where(c.Column+" > ?", c.Value)
+	case OperatorGte:
+		return query.Where(c.Column+" >= ?", c.Value)
+	case OperatorLike:
+		return query.Where(c.Column+" LIKE ?", c.Value)
+	case OperatorIlike:
+		return query.Where(c.Column+" ILIKE ?", c.Value)
+	case OperatorIn:
+		return query.Where(c.Column+" IN ?", c.Value)
+	case OperatorNull:
+		if isNull, _ := c.Value.(bool); isNull {
+			return query.Where(c.Column + " IS NULL")
+		}
+		return query.Where(c.Column + " IS NOT NULL")
+	default:
+		return query.Where(c.Column+" = ?", c.Value)
+	}
+}
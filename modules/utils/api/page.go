@@ -0,0 +1,133 @@
+package api
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Page is a page of a paginated list
+// The links to the next and previous pages are null on the last and first pages
+//
+//	@description	Page of a paginated list
+type Page[T any] struct {
+	Items []T   `json:"items"`
+	Total int64 `json:"total" example:"42"`
+	// Page is the page number, it is omitted when the list is paginated with cursors
+	Page  int     `json:"page,omitempty" example:"1"`
+	Limit int     `json:"limit"          example:"20"`
+	Next  *string `json:"next"           example:"/api/users?limit=20&page=2"`
+	Prev  *string `json:"prev"`
+	// NextCursor is the cursor of the next page, it is only set when the list is paginated with cursors
+	NextCursor *string `json:"next_cursor,omitempty"`
+}
+
+// Pager is a page, whatever the type of its items
+type Pager interface {
+	links() map[string]*string
+}
+
+// NewPage creates the page of a list from the items found with the filter
+// and the total number of items matching the filter
+func NewPage[T any](ctx *gin.Context, items []T, total int64, filter Filter) *Page[T] {
+	page := &Page[T]{
+		Items: items,
+		Total: total,
+		Page:  1,
+		Limit: DefaultLimit,
+	}
+	if page.Items == nil {
+		page.Items = []T{}
+	}
+	if filter.Page != nil {
+		page.Page = *filter.Page
+	}
+	if filter.Limit != nil {
+		page.Limit = *filter.Limit
+	}
+
+	// The lists paginated with cursors are only browsed forward
+	// One more item than the limit is found to know if there is a next page
+	if filter.Keyset {
+		page.Page = 0
+		if len(page.Items) > page.Limit {
+			page.Items = page.Items[:page.Limit]
+			cursor, err := filter.nextCursor(&page.Items[page.Limit-1])
+			if err != nil {
+				log.Ctx(ctx.Request.Context()).Error().Err(err).Msg("Creating the next cursor")
+				return page
+			}
+			page.NextCursor = &cursor
+			page.Next = cursorLink(ctx.Request.URL, cursor, page.Limit)
+		}
+		return page
+	}
+
+	// Links to the other pages keep the query parameters of the request
+	if int64(page.Page*page.Limit) < total {
+		page.Next = pageLink(ctx.Request.URL, page.Page+1, page.Limit)
+	}
+	if page.Page > 1 {
+		page.Prev = pageLink(ctx.Request.URL, page.Page-1, page.Limit)
+	}
+
+	return page
+}
+
+// MapPage converts the items of a page, the pagination is kept
+func MapPage[T any, U any](page *Page[T], convert func(T) U) *Page[U] {
+	items := make([]U, len(page.Items))
+	for i, item := range page.Items {
+		items[i] = convert(item)
+	}
+	return &Page[U]{
+		Items:      items,
+		Total:      page.Total,
+		Page:       page.Page,
+		Limit:      page.Limit,
+		Next:       page.Next,
+		Prev:       page.Prev,
+		NextCursor: page.NextCursor,
+	}
+}
+
+// links returns the links to the other pages by relation type
+func (p *Page[T]) links() map[string]*string {
+	return map[string]*string{"next": p.Next, "prev": p.Prev}
+}
+
+// pageLink returns the url of the request for another page
+func pageLink(u *url.URL, page int, limit int) *string {
+	query := u.Query()
+	query.Set("page", strconv.Itoa(page))
+	query.Set("limit", strconv.Itoa(limit))
+	link := (&url.URL{Path: u.Path, RawQuery: query.Encode()}).String()
+	return &link
+}
+
+// cursorLink returns the url of the request for the page after the cursor
+func cursorLink(u *url.URL, cursor string, limit int) *string {
+	query := u.Query()
+	query.Set("cursor", cursor)
+	query.Set("limit", strconv.Itoa(limit))
+	link := (&url.URL{Path: u.Path, RawQuery: query.Encode()}).String()
+	return &link
+}
+
+// SendPage is used to send a page
+// The links to the other pages are also sent in the Link header
+func (s *Success) SendPage(page Pager) {
+	var links []string
+	for _, rel := range []string{"next", "prev"} {
+		if link := page.links()[rel]; link != nil {
+			links = append(links, fmt.Sprintf(`<%s>; rel="%s"`, *link, rel))
+		}
+	}
+	if len(links) > 0 {
+		s.r.ctx.Header("Link", strings.Join(links, ", "))
+	}
+	s.SendRaw(page)
+}
@@ -0,0 +1,410 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-api-template/go-backend/modules/i18n"
+	"io"
+	"mime"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Media types of the bodies of the PATCH requests
+const (
+	// ContentTypeMergePatch is a JSON Merge Patch, as defined by RFC 7396
+	// A plain application/json body is handled as a merge patch
+	ContentTypeMergePatch = "application/merge-patch+json"
+	// ContentTypeJSONPatch is a JSON Patch, as defined by RFC 6902
+	ContentTypeJSONPatch = "application/json-patch+json"
+)
+
+// Operation is an operation of a JSON Patch
+//
+//	@description	Operation of a JSON Patch, as defined by RFC 6902
+type Operation struct {
+	Op    string          `json:"op"              example:"replace"`
+	Path  string          `json:"path"            example:"/first_name"`
+	From  string          `json:"from,omitempty"`
+	Value json.RawMessage `json:"value,omitempty" swaggertype:"string" example:"John"`
+}
+
+// errPatchTest is returned when a test operation of a JSON Patch fails
+var errPatchTest = errors.New("the test operation failed")
+
+// Patch applies the patch of the request body to the payload
+// The payload is the current state of the resource, only its editable fields can be changed
+// and a field is cleared when it is set to null or removed.
+// The response is sent if the patch cannot be applied, the caller must then stop handling the request
+func Patch(ctx *gin.Context, payload any, editable []string) bool {
+	// Get the current document
+	data, err := json.Marshal(payload)
+	if err != nil {
+		Ctx(ctx).InternalServerError().WithError(err).Send()
+		return false
+	}
+	var before map[string]any
+	if err := json.Unmarshal(data, &before); err != nil {
+		Ctx(ctx).InternalServerError().WithError(err).Send()
+		return false
+	}
+
+	// Read the patch
+	body, err := io.ReadAll(ctx.Request.Body)
+	if err != nil {
+		SendBodyError(ctx, err)
+		return false
+	}
+
+	// Apply the patch to a copy of the document
+	var after any
+	contentType, _, _ := mime.ParseMediaType(ctx.GetHeader("Content-Type"))
+	switch contentType {
+	case ContentTypeMergePatch, binding.MIMEJSON, "":
+		var patch any
+		if err := json.Unmarshal(body, &patch); err != nil {
+			Ctx(ctx).BadRequest().WithCode(CodeInvalidBody).WithError(err).Send()
+			return false
+		}
+		after = mergePatch(clone(before), patch)
+	case ContentTypeJSONPatch:
+		var operations []Operation
+		if err := json.Unmarshal(body, &operations); err != nil {
+			Ctx(ctx).BadRequest().WithCode(CodeInvalidPatch).WithError(err).Send()
+			return false
+		}
+		after, err = jsonPatch(clone(before), operations)
+		if errors.Is(err, errPatchTest) {
+			Ctx(ctx).Conflict().WithCode(CodePatchTestFailed).WithError(err).Send()
+			return false
+		}
+		if err != nil {
+			Ctx(ctx).BadRequest().WithCode(CodeInvalidPatch).WithError(err).Send()
+			return false
+		}
+	default:
+		Ctx(ctx).UnsupportedMediaType().
+			WithCode(CodeUnsupportedMediaType).
+			WithDescription("The body must be a JSON Merge Patch or a JSON Patch").
+			Send()
+		return false
+	}
+	document, ok := after.(map[string]any)
+	if !ok {
+		Ctx(ctx).BadRequest().WithCode(CodeInvalidPatch).WithDescription("The patched document must be an object").Send()
+		return false
+	}
+
+	// Only the editable fields can be changed
+	locale := i18n.Locale(ctx)
+	var fields []FieldError
+	for _, name := range changedKeys(before, document) {
+		if !slices.Contains(editable, name) {
+			fields = append(fields, FieldError{
+				Field:   name,
+				Rule:    "readonly",
+				Message: i18n.Translate(locale, "{field} cannot be updated", i18n.Params{"field": name}),
+			})
+		}
+	}
+	if len(fields) > 0 {
+		Ctx(ctx).BadRequest().
+			WithCode(CodeValidationFailed).
+			WithDescription("The request body is not valid").
+			WithFieldErrors(fields).
+			Send()
+		return false
+	}
+
+	// Replace the payload by the patched document and validate it
+	data, err = json.Marshal(document)
+	if err == nil {
+		value := reflect.ValueOf(payload).Elem()
+		value.Set(reflect.Zero(value.Type()))
+		err = json.Unmarshal(data, payload)
+	}
+	if err == nil {
+		err = binding.Validator.ValidateStruct(payload)
+	}
+	if err != nil {
+		SendBodyError(ctx, err)
+		return false
+	}
+
+	return true
+}
+
+// mergePatch applies a JSON Merge Patch to a document
+// A null value removes the member, an object is merged and any other value replaces the member
+func mergePatch(document any, patch any) any {
+	patchObject, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+	object, ok := document.(map[string]any)
+	if !ok {
+		object = map[string]any{}
+	}
+	for key, value := range patchObject {
+		if value == nil {
+			delete(object, key)
+		} else {
+			object[key] = mergePatch(object[key], value)
+		}
+	}
+	return object
+}
+
+// jsonPatch applies the operations of a JSON Patch to a document
+// The operations are applied in order, the patch fails as a whole if one of them fails
+func jsonPatch(document any, operations []Operation) (any, error) {
+	var err error
+	for i, operation := range operations {
+		var value any
+		if operation.Value != nil {
+			if err := json.Unmarshal(operation.Value, &value); err != nil {
+				return nil, fmt.Errorf("operation %d: %w", i, err)
+			}
+		}
+
+		switch operation.Op {
+		case "add":
+			if operation.Value == nil {
+				return nil, fmt.Errorf("operation %d: the value is missing", i)
+			}
+			document, err = pointerAdd(document, operation.Path, value)
+		case "remove":
+			document, _, err = pointerRemove(document, operation.Path)
+		case "replace":
+			if operation.Value == nil {
+				return nil, fmt.Errorf("operation %d: the value is missing", i)
+			}
+			document, _, err = pointerRemove(document, operation.Path)
+			if err == nil {
+				document, err = pointerAdd(document, operation.Path, value)
+			}
+		case "move":
+			var moved any
+			document, moved, err = pointerRemove(document, operation.From)
+			if err == nil {
+				document, err = pointerAdd(document, operation.Path, moved)
+			}
+		case "copy":
+			var copied any
+			copied, err = pointerGet(document, operation.From)
+			if err == nil {
+				document, err = pointerAdd(document, operation.Path, clone(copied))
+			}
+		case "test":
+			var current any
+			current, err = pointerGet(document, operation.Path)
+			if err == nil && !reflect.DeepEqual(current, value) {
+				err = fmt.Errorf("%w on %s", errPatchTest, operation.Path)
+			}
+		default:
+			err = fmt.Errorf("unknown operation %q", operation.Op)
+		}
+		if err != nil {
+			if errors.Is(err, errPatchTest) {
+				return nil, err
+			}
+			return nil, fmt.Errorf("operation %d: %w", i, err)
+		}
+	}
+	return document, nil
+}
+
+// parsePointer splits a JSON Pointer, as defined by RFC 6901, into its reference tokens
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("invalid path %q, it must start with a slash", pointer)
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.NewReplacer("~1", "/", "~0", "~").Replace(token)
+	}
+	return tokens, nil
+}
+
+// arrayIndex returns the index of an array element referenced by a token
+// The token "-" references the element after the last one, it is only allowed when adding
+func arrayIndex(token string, length int, adding bool) (int, error) {
+	if token == "-" && adding {
+		return length, nil
+	}
+	index, err := strconv.Atoi(token)
+	if err != nil || index < 0 || index > length || (index == length && !adding) {
+		return 0, fmt.Errorf("invalid array index %q", token)
+	}
+	return index, nil
+}
+
+// pointerGet returns the value referenced by a JSON Pointer
+func pointerGet(document any, pointer string) (any, error) {
+	tokens, err := parsePointer(pointer)
+	if err != nil {
+		return nil, err
+	}
+	current := document
+	for _, token := range tokens {
+		switch node := current.(type) {
+		case map[string]any:
+			value, ok := node[token]
+			if !ok {
+				return nil, fmt.Errorf("path %q not found", pointer)
+			}
+			current = value
+		case []any:
+			index, err := arrayIndex(token, len(node), false)
+			if err != nil {
+				return nil, err
+			}
+			current = node[index]
+		default:
+			return nil, fmt.Errorf("path %q not found", pointer)
+		}
+	}
+	return current, nil
+}
+
+// pointerAdd adds a value at the location referenced by a JSON Pointer
+// The member of an object is replaced, the value is inserted in an array
+func pointerAdd(document any, pointer string, value any) (any, error) {
+	tokens, err := parsePointer(pointer)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return value, nil
+	}
+	parentPointer := pointer[:strings.LastIndex(pointer, "/")]
+	parent, err := pointerGet(document, parentPointer)
+	if err != nil {
+		return nil, err
+	}
+	token := tokens[len(tokens)-1]
+	switch node := parent.(type) {
+	case map[string]any:
+		node[token] = value
+		return document, nil
+	case []any:
+		index, err := arrayIndex(token, len(node), true)
+		if err != nil {
+			return nil, err
+		}
+		return pointerSet(document, parentPointer, slices.Insert(node, index, value))
+	default:
+		return nil, fmt.Errorf("path %q not found", pointer)
+	}
+}
+
+// pointerSet replaces the value referenced by a JSON Pointer
+func pointerSet(document any, pointer string, value any) (any, error) {
+	tokens, err := parsePointer(pointer)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return value, nil
+	}
+	parent, err := pointerGet(document, pointer[:strings.LastIndex(pointer, "/")])
+	if err != nil {
+		return nil, err
+	}
+	token := tokens[len(tokens)-1]
+	switch node := parent.(type) {
+	case map[string]any:
+		node[token] = value
+	case []any:
+		index, err := arrayIndex(token, len(node), false)
+		if err != nil {
+			return nil, err
+		}
+		node[index] = value
+	default:
+		return nil, fmt.Errorf("path %q not found", pointer)
+	}
+	return document, nil
+}
+
+// pointerRemove removes the value referenced by a JSON Pointer and returns it
+func pointerRemove(document any, pointer string) (any, any, error) {
+	tokens, err := parsePointer(pointer)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, document, nil
+	}
+	parentPointer := pointer[:strings.LastIndex(pointer, "/")]
+	parent, err := pointerGet(document, parentPointer)
+	if err != nil {
+		return nil, nil, err
+	}
+	token := tokens[len(tokens)-1]
+	switch node := parent.(type) {
+	case map[string]any:
+		value, ok := node[token]
+		if !ok {
+			return nil, nil, fmt.Errorf("path %q not found", pointer)
+		}
+		delete(node, token)
+		return document, value, nil
+	case []any:
+		index, err := arrayIndex(token, len(node), false)
+		if err != nil {
+			return nil, nil, err
+		}
+		value := node[index]
+		document, err = pointerSet(document, parentPointer, slices.Delete(slices.Clone(node), index, index+1))
+		return document, value, err
+	default:
+		return nil, nil, fmt.Errorf("path %q not found", pointer)
+	}
+}
+
+// clone returns a deep copy of a decoded JSON value
+func clone(value any) any {
+	switch node := value.(type) {
+	case map[string]any:
+		object := make(map[string]any, len(node))
+		for key, value := range node {
+			object[key] = clone(value)
+		}
+		return object
+	case []any:
+		array := make([]any, len(node))
+		for i, value := range node {
+			array[i] = clone(value)
+		}
+		return array
+	default:
+		return value
+	}
+}
+
+// changedKeys returns the sorted keys whose value differs between two objects
+func changedKeys(before map[string]any, after map[string]any) []string {
+	var keys []string
+	for key, value := range before {
+		if other, ok := after[key]; !ok || !reflect.DeepEqual(value, other) {
+			keys = append(keys, key)
+		}
+	}
+	for key := range after {
+		if _, ok := before[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
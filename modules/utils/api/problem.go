@@ -0,0 +1,215 @@
+package api
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgconn"
+	"net"
+	"net/http"
+)
+
+// ContentTypeProblem is the media type of the error responses, as defined by RFC 7807
+const ContentTypeProblem = "application/problem+json"
+
+// RequestIdKey is the key of the request id in the gin context
+const RequestIdKey = "request_id"
+
+// Code is a stable and machine-readable error code
+// The clients can rely on it, a code is never renamed nor reused
+type Code string
+
+// Catalogue of the error codes
+const (
+	// Generic codes, used when no specific code is given
+	CodeBadRequest           Code = "bad_request"
+	CodeUnauthorized         Code = "unauthorized"
+	CodeForbidden            Code = "forbidden"
+	CodeNotFound             Code = "not_found"
+	CodeConflict             Code = "conflict"
+	CodeGone                 Code = "gone"
+	CodePreconditionFailed   Code = "precondition_failed"
+	CodePreconditionRequired Code = "precondition_required"
+	CodeInternal             Code = "internal_error"
+	CodeNotImplemented       Code = "not_implemented"
+	CodeBadGateway           Code = "bad_gateway"
+	CodeUnsupportedMediaType Code = "unsupported_media_type"
+	CodeTooManyRequests      Code = "too_many_requests"
+	CodeRequestTooLarge      Code = "request_too_large"
+
+	// Request bodies
+	CodeInvalidBody      Code = "invalid_body"
+	CodeValidationFailed Code = "validation_failed"
+	CodeInvalidPatch     Code = "invalid_patch"
+	CodePatchTestFailed  Code = "patch_test_failed"
+	CodeResourceModified Code = "resource_modified"
+
+	// Idempotent requests
+	CodeInvalidIdempotencyKey Code = "invalid_idempotency_key"
+	CodeIdempotencyKeyReused  Code = "idempotency_key_reused"
+	CodeIdempotencyKeyInUse   Code = "idempotency_key_in_use"
+
+	// List queries
+	CodeInvalidFilter    Code = "invalid_filter"
+	CodeInvalidSelection Code = "invalid_selection"
+
+	// Authentication and accounts
+	CodeNotAuthenticated       Code = "not_authenticated"
+	CodeInvalidCredentials     Code = "invalid_credentials"
+	CodeInvalidToken           Code = "invalid_token"
+	CodeAccountNotVerified     Code = "account_not_verified"
+	CodeAccountAlreadyVerified Code = "account_already_verified"
+	CodeAccountExists          Code = "account_exists"
+	CodeRegistrationDenied     Code = "registration_denied"
+	CodePlatformAdminRequired  Code = "platform_admin_required"
+	CodeUserNotFound           Code = "user_not_found"
+
+	// Organizations
+	CodeInvalidOrganizationId    Code = "invalid_organization_id"
+	CodeOrganizationNotFound     Code = "organization_not_found"
+	CodeNotOrganizationMember    Code = "not_organization_member"
+	CodeOrganizationRoleRequired Code = "organization_role_required"
+	CodeOwnerRoleRequired        Code = "owner_role_required"
+	CodeMemberNotFound           Code = "member_not_found"
+	CodeOrganizationSlugTaken    Code = "organization_slug_taken"
+	CodeAlreadyMember            Code = "already_member"
+	CodeLastOwner                Code = "last_owner"
+
+	// Invitations
+	CodeInvitationNotFound      Code = "invitation_not_found"
+	CodeInvitationExists        Code = "invitation_exists"
+	CodeInvitationAccepted      Code = "invitation_accepted"
+	CodeInvitationExpired       Code = "invitation_expired"
+	CodeInvitationEmailMismatch Code = "invitation_email_mismatch"
+)
+
+// titles are the human-readable summaries of the codes
+// They never change from one occurrence of a problem to another
+var titles = map[Code]string{
+	CodeBadRequest:           "Bad request",
+	CodeUnauthorized:         "Unauthorized",
+	CodeForbidden:            "Forbidden",
+	CodeNotFound:             "Not found",
+	CodeConflict:             "Conflict",
+	CodeGone:                 "Gone",
+	CodePreconditionFailed:   "Precondition failed",
+	CodePreconditionRequired: "Precondition required",
+	CodeInternal:             "Internal error",
+	CodeNotImplemented:       "Not implemented",
+	CodeBadGateway:           "Bad gateway",
+	CodeUnsupportedMediaType: "Unsupported media type",
+	CodeTooManyRequests:      "Too many requests",
+	CodeRequestTooLarge:      "Request too large",
+
+	CodeInvalidBody:      "Invalid request body",
+	CodeValidationFailed: "Validation failed",
+	CodeInvalidPatch:     "Invalid patch",
+	CodePatchTestFailed:  "Patch test failed",
+	CodeResourceModified: "Resource modified",
+
+	CodeInvalidIdempotencyKey: "Invalid idempotency key",
+	CodeIdempotencyKeyReused:  "Idempotency key reused",
+	CodeIdempotencyKeyInUse:   "Idempotency key in use",
+
+	CodeInvalidFilter:    "Invalid filter",
+	CodeInvalidSelection: "Invalid selection of fields",
+
+	CodeNotAuthenticated:       "Not authenticated",
+	CodeInvalidCredentials:     "Invalid credentials",
+	CodeInvalidToken:           "Invalid token",
+	CodeAccountNotVerified:     "Account not verified",
+	CodeAccountAlreadyVerified: "Account already verified",
+	CodeAccountExists:          "Account already exists",
+	CodeRegistrationDenied:     "Registration denied",
+	CodePlatformAdminRequired:  "Platform admin required",
+	CodeUserNotFound:           "User not found",
+
+	CodeInvalidOrganizationId:    "Invalid organization id",
+	CodeOrganizationNotFound:     "Organization not found",
+	CodeNotOrganizationMember:    "Not a member of the organization",
+	CodeOrganizationRoleRequired: "Organization role required",
+	CodeOwnerRoleRequired:        "Owner role required",
+	CodeMemberNotFound:           "Member not found",
+	CodeOrganizationSlugTaken:    "Organization slug already taken",
+	CodeAlreadyMember:            "Already a member of the organization",
+	CodeLastOwner:                "Last owner of the organization",
+
+	CodeInvitationNotFound:      "Invitation not found",
+	CodeInvitationExists:        "Invitation already sent",
+	CodeInvitationAccepted:      "Invitation already accepted",
+	CodeInvitationExpired:       "Invitation expired",
+	CodeInvitationEmailMismatch: "Invitation sent to another email address",
+}
+
+// statusCodes are the codes used when an error response is sent without a code
+var statusCodes = map[int]Code{
+	http.StatusBadRequest:            CodeBadRequest,
+	http.StatusUnauthorized:          CodeUnauthorized,
+	http.StatusForbidden:             CodeForbidden,
+	http.StatusNotFound:              CodeNotFound,
+	http.StatusConflict:              CodeConflict,
+	http.StatusGone:                  CodeGone,
+	http.StatusPreconditionFailed:    CodePreconditionFailed,
+	http.StatusRequestEntityTooLarge: CodeRequestTooLarge,
+	http.StatusUnsupportedMediaType:  CodeUnsupportedMediaType,
+	http.StatusPreconditionRequired:  CodePreconditionRequired,
+	http.StatusTooManyRequests:       CodeTooManyRequests,
+	http.StatusInternalServerError:   CodeInternal,
+	http.StatusNotImplemented:        CodeNotImplemented,
+	http.StatusBadGateway:            CodeBadGateway,
+}
+
+// Type returns the URI identifying the code
+func (c Code) Type() string {
+	return "urn:problem-type:" + string(c)
+}
+
+// Title returns the human-readable summary of the code
+func (c Code) Title() string {
+	if title, ok := titles[c]; ok {
+		return title
+	}
+	return string(c)
+}
+
+// Problem is an error response, as defined by RFC 7807
+//
+//	@description	Error response as defined by RFC 7807, sent as application/problem+json
+type Problem struct {
+	// Type identifies the kind of problem
+	Type string `json:"type" example:"urn:problem-type:user_not_found"`
+	// Title is a short summary of the kind of problem
+	Title string `json:"title" example:"User not found"`
+	// Status is the http status code
+	Status int `json:"status" example:"404"`
+	// Detail explains this occurrence of the problem
+	Detail string `json:"detail,omitempty" example:"user not found"`
+	// Instance is the path of the request
+	Instance string `json:"instance,omitempty" example:"/api/users/6b0f8e1e-6b4f-4b8a-9e6a-0b8f0b8f0b8f"`
+	// Code is the stable and machine-readable code of the problem
+	Code Code `json:"code" example:"user_not_found"`
+	// Errors are the invalid fields of the request body, if any
+	Errors []FieldError `json:"errors,omitempty"`
+	// RequestId identifies the request in the logs
+	RequestId string `json:"request_id,omitempty" example:"c0a8012e-7f7c-4b9a-8c6a-2d5b7f3e9a10"`
+}
+
+// requestId returns the id of the request
+func requestId(ctx *gin.Context) string {
+	if id := ctx.GetString(RequestIdKey); id != "" {
+		return id
+	}
+	return ctx.GetHeader("X-Request-ID")
+}
+
+// isInternal returns true if the error comes from the infrastructure,
+// such as the database, and must not be sent to the client
+func isInternal(err error) bool {
+	var pgError *pgconn.PgError
+	var netError *net.OpError
+	return errors.As(err, &pgError) ||
+		errors.As(err, &netError) ||
+		errors.Is(err, driver.ErrBadConn) ||
+		errors.Is(err, context.DeadlineExceeded)
+}
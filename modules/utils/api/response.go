@@ -1,10 +1,13 @@
 package api
 
 import (
-	"encoding/json"
 	"errors"
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/render"
+	"github.com/go-api-template/go-backend/modules/i18n"
+	"github.com/rs/zerolog/log"
 	"net/http"
+	"strings"
 )
 
 // Response is a wrapper for gin.Context
@@ -25,13 +28,13 @@ type Success struct {
 }
 
 // Error is a struct for error response
-// It is used when the response is an error
+// It is used when the response is an error, it is sent as a Problem
 type Error struct {
 	r           *Response
-	Code        string  `json:"code,omitempty" example:"server_code"`
-	Description string  `json:"description,omitempty" example:"a server message"`
-	Errors      []error `json:"errors,omitempty"`
-	Data        any     `json:"data,omitempty"`
+	code        Code
+	description string
+	errors      []error
+	fields      []FieldError
 }
 
 // Ctx is a constructor for Response
@@ -65,6 +68,12 @@ func (s *Success) WithData(data any) *Success {
 	return s
 }
 
+// WithETag set the entity tag of the sent version of the resource
+func (s *Success) WithETag(etag string) *Success {
+	s.r.ctx.Header("ETag", etag)
+	return s
+}
+
 // SendRaw is used to send the response with raw data
 func (s *Success) SendRaw(raw any) {
 	s.raw = &raw
@@ -72,81 +81,115 @@ func (s *Success) SendRaw(raw any) {
 }
 
 // Send is used to send the response
+// The description is translated in the locale of the request
 func (s *Success) Send() {
 	if s.raw != nil {
 		s.r.ctx.JSON(s.r.status, s.raw)
 		return
 	}
+	s.Description = i18n.Translate(i18n.Locale(s.r.ctx), s.Description, nil)
 	s.r.ctx.JSON(s.r.status, s)
 }
 
 // WithCode set the code of the response
-func (e *Error) WithCode(code string) *Error {
-	e.Code = code
+// The code must be taken from the catalogue
+func (e *Error) WithCode(code Code) *Error {
+	e.code = code
 	return e
 }
 
 // WithDescription set the description of the response
+// It is sent as the detail of the problem
 func (e *Error) WithDescription(description string) *Error {
-	e.Description = description
+	e.description = description
 	return e
 }
 
 // WithErrors set the errors of the response
 func (e *Error) WithErrors(errors []error) *Error {
-	e.Errors = errors
+	e.errors = errors
 	return e
 }
 
-// WithError add an error to the errors of the response
-func (e *Error) WithError(err error) *Error {
-	e.Errors = append(e.Errors, err)
+// WithFieldErrors set the invalid fields of the request body
+func (e *Error) WithFieldErrors(fields []FieldError) *Error {
+	e.fields = fields
 	return e
 }
 
-// WithData set the data of the response
-func (e *Error) WithData(data *any) *Error {
-	e.Data = data
+// WithError add an error to the errors of the response
+func (e *Error) WithError(err error) *Error {
+	e.errors = append(e.errors, err)
 	return e
 }
 
-// Send is used to send the response
-func (e *Error) Send() {
-	if len(e.Errors) == 0 && e.Description != "" {
-		e.Errors = append(e.Errors, errors.New(e.Description))
+// Problem returns the problem sent to the client
+// The internal errors, such as the database errors, are never sent:
+// they are logged with the request id and the route, never the path which may hold tokens,
+// and replaced by a safe message
+// The title and the detail are translated in the locale of the request
+func (e *Error) Problem() *Problem {
+	ctx := e.r.ctx
+	status := e.r.status
+	locale := i18n.Locale(ctx)
+	p := &Problem{
+		Status:    status,
+		Code:      e.code,
+		Detail:    e.description,
+		Instance:  ctx.Request.URL.Path,
+		Errors:    e.fields,
+		RequestId: requestId(ctx),
 	}
-	if len(e.Errors) > 0 && e.Description == "" {
-		e.Description = errors.Join(e.Errors...).Error()
+
+	// Hide the internal errors
+	internal := status >= http.StatusInternalServerError
+	for _, err := range e.errors {
+		internal = internal || isInternal(err)
 	}
-	e.r.ctx.JSON(e.r.status, e)
-}
-
-// MarshalJSON is used to marshal the Error struct
-// With this, we can add the errors to the response
-func (e *Error) MarshalJSON() ([]byte, error) {
-	// Define an alias for the error struct
-	// with Errors as []string
-	type ErrorAlias struct {
-		Code        string   `json:"code,omitempty"`
-		Description string   `json:"description,omitempty"`
-		Errors      []string `json:"errors,omitempty"`
+	if internal {
+		log.Ctx(ctx.Request.Context()).Error().
+			Err(errors.Join(e.errors...)).
+			Int("status", status).
+			Str("route", ctx.FullPath()).
+			Msg(e.description)
+		if status < http.StatusInternalServerError {
+			p.Status = http.StatusInternalServerError
+			p.Code = ""
+		}
+		p.Detail = i18n.Translate(locale, "An internal error occurred, please retry later", nil)
+		if p.RequestId != "" {
+			p.Detail = i18n.Translate(locale,
+				"An internal error occurred, please retry later or contact the support with the request id {request_id}",
+				i18n.Params{"request_id": p.RequestId})
+		}
+	} else if p.Detail != "" {
+		p.Detail = i18n.Translate(locale, p.Detail, nil)
+	} else if len(e.errors) > 0 {
+		details := make([]string, len(e.errors))
+		for i, err := range e.errors {
+			details[i] = i18n.Translate(locale, err.Error(), nil)
+		}
+		p.Detail = strings.Join(details, "\n")
 	}
 
-	// Create an instance of the alias
-	a := &ErrorAlias{
-		Code:        e.Code,
-		Description: e.Description,
+	// Use the generic code of the status if no code is given
+	if p.Code == "" {
+		p.Code = statusCodes[p.Status]
 	}
-
-	// Add the errors to the alias
-	if e.Errors != nil {
-		for _, err := range e.Errors {
-			a.Errors = append(a.Errors, err.Error())
-		}
+	if p.Code == "" {
+		p.Code = CodeBadRequest
 	}
+	p.Type = p.Code.Type()
+	p.Title = i18n.Translate(locale, p.Code.Title(), nil)
 
-	// Marshal the alias
-	return json.Marshal(a)
+	return p
+}
+
+// Send is used to send the response
+func (e *Error) Send() {
+	p := e.Problem()
+	e.r.ctx.Header("Content-Type", ContentTypeProblem)
+	e.r.ctx.Render(p.Status, render.JSON{Data: p})
 }
 
 // Ok Status 200
@@ -209,12 +252,42 @@ func (r *Response) Conflict() *Error {
 	return &Error{r: r}
 }
 
+// Gone Status 410
+func (r *Response) Gone() *Error {
+	r.status = http.StatusGone
+	return &Error{r: r}
+}
+
 // PreconditionFailed Status 412
 func (r *Response) PreconditionFailed() *Error {
 	r.status = http.StatusPreconditionFailed
 	return &Error{r: r}
 }
 
+// RequestEntityTooLarge Status 413
+func (r *Response) RequestEntityTooLarge() *Error {
+	r.status = http.StatusRequestEntityTooLarge
+	return &Error{r: r}
+}
+
+// UnsupportedMediaType Status 415
+func (r *Response) UnsupportedMediaType() *Error {
+	r.status = http.StatusUnsupportedMediaType
+	return &Error{r: r}
+}
+
+// PreconditionRequired Status 428
+func (r *Response) PreconditionRequired() *Error {
+	r.status = http.StatusPreconditionRequired
+	return &Error{r: r}
+}
+
+// TooManyRequests Status 429
+func (r *Response) TooManyRequests() *Error {
+	r.status = http.StatusTooManyRequests
+	return &Error{r: r}
+}
+
 // InternalServerError Status 500
 func (r *Response) InternalServerError() *Error {
 	r.status = http.StatusInternalServerError
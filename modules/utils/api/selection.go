@@ -0,0 +1,201 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Selection holds the fields and the relationships requested by a client
+// with the fields and include query parameters, such as ?fields=id,email&include=memberships
+type Selection struct {
+	// Fields are the json names of the requested fields, all the fields are sent when it is empty
+	Fields []string
+	// Columns are the database columns of the requested fields and of the primary key
+	Columns []string
+	// Includes are the json names of the requested relationships
+	Includes []string
+	// preloads are the names of the requested relationships in the model
+	preloads []string
+}
+
+// GetSelection creates a selection from the query parameters
+// The fields and the relationships are validated against the json fields of the model,
+// otherwise an error listing the allowed ones is returned
+func GetSelection(c *gin.Context, model any) (Selection, error) {
+	var selection Selection
+
+	s, err := schema.Parse(model, schemas, schema.NamingStrategy{})
+	if err != nil {
+		return selection, err
+	}
+
+	// Get the fields and the relationships which can be requested
+	columns := map[string]string{}
+	relations := map[string]string{}
+	for _, field := range s.Fields {
+		name := jsonName(field)
+		if name == "" {
+			continue
+		}
+		if relation, ok := s.Relationships.Relations[field.Name]; ok {
+			relations[name] = relation.Name
+		} else if field.DBName != "" {
+			columns[name] = s.Table + "." + field.DBName
+		}
+	}
+
+	// Get the requested fields, the primary key is always selected
+	for _, name := range queryList(c, "fields") {
+		column, ok := columns[name]
+		if !ok {
+			return selection, fmt.Errorf("unknown field %s, the allowed fields are: %s", name, joinKeys(columns))
+		}
+		if !slices.Contains(selection.Fields, name) {
+			selection.Fields = append(selection.Fields, name)
+			selection.Columns = append(selection.Columns, column)
+		}
+	}
+	if len(selection.Fields) > 0 && s.PrioritizedPrimaryField != nil {
+		selection = selection.WithColumns(s.Table + "." + s.PrioritizedPrimaryField.DBName)
+	}
+
+	// Get the requested relationships
+	for _, name := range queryList(c, "include") {
+		relation, ok := relations[name]
+		if !ok {
+			return selection, fmt.Errorf("unknown include %s, the allowed includes are: %s", name, joinKeys(relations))
+		}
+		if !slices.Contains(selection.Includes, name) {
+			selection.Includes = append(selection.Includes, name)
+			selection.preloads = append(selection.preloads, relation)
+		}
+	}
+
+	return selection, nil
+}
+
+// WithColumns returns the selection with more columns to select, if some fields are selected
+// It is used to select the columns needed by the query, such as the sorted columns
+func (s Selection) WithColumns(columns ...string) Selection {
+	if len(s.Columns) == 0 {
+		return s
+	}
+	selected := slices.Clone(s.Columns)
+	for _, column := range columns {
+		if !slices.Contains(selected, column) {
+			selected = append(selected, column)
+		}
+	}
+	s.Columns = selected
+	return s
+}
+
+// Apply the selection to the query
+// Only the requested columns are selected and the requested relationships are preloaded
+func (s Selection) Apply(query *gorm.DB) *gorm.DB {
+	if len(s.Columns) > 0 {
+		query = query.Select(s.Columns)
+	}
+	for _, preload := range s.preloads {
+		query = query.Preload(preload)
+	}
+	return query
+}
+
+// Render returns the item with only the requested fields and relationships
+// The item is returned as is when all the fields are requested
+func (s Selection) Render(item any) any {
+	if len(s.Fields) == 0 {
+		return item
+	}
+
+	// Keep the requested keys of the serialized item
+	data, err := json.Marshal(item)
+	if err != nil {
+		return item
+	}
+	all := map[string]json.RawMessage{}
+	if err := json.Unmarshal(data, &all); err != nil {
+		return item
+	}
+	rendered := map[string]json.RawMessage{}
+	for _, key := range append(slices.Clone(s.Fields), s.Includes...) {
+		if value, ok := all[key]; ok {
+			rendered[key] = value
+		}
+	}
+
+	return rendered
+}
+
+// ETag returns the entity tag of a rendered item
+// The tag of the resource is kept when the whole resource is sent.
+// A partial representation, or one with relationships which are not versioned with the resource,
+// gets a weak tag hashed from its content: it can be revalidated but never used to modify the resource
+func (s Selection) ETag(etag string, rendered any) string {
+	if len(s.Fields) == 0 && len(s.Includes) == 0 {
+		return etag
+	}
+	data, err := json.Marshal(rendered)
+	if err != nil {
+		return ""
+	}
+	return NewWeakETag(data)
+}
+
+// SortColumns returns the sorted columns of the filter
+func (f Filter) SortColumns() []string {
+	columns := make([]string, len(f.Sort))
+	for i, s := range f.Sort {
+		columns[i] = s.Column
+	}
+	return columns
+}
+
+// jsonName returns the name of the field in json, or an empty string if it is not serialized
+func jsonName(field *schema.Field) string {
+	return structFieldName(field.StructField)
+}
+
+// structFieldName returns the json name of a struct field, it is empty if the field is not serialized
+func structFieldName(field reflect.StructField) string {
+	tag, ok := field.Tag.Lookup("json")
+	if !ok {
+		return ""
+	}
+	name, _, _ := strings.Cut(tag, ",")
+	if name == "-" {
+		return ""
+	}
+	return name
+}
+
+// queryList returns the comma separated values of a query parameter
+func queryList(c *gin.Context, key string) []string {
+	var values []string
+	for _, param := range c.QueryArray(key) {
+		for _, value := range strings.Split(param, ",") {
+			if value = strings.TrimSpace(value); value != "" {
+				values = append(values, value)
+			}
+		}
+	}
+	return values
+}
+
+// joinKeys returns the sorted keys of the map separated by a comma
+func joinKeys(m map[string]string) string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return strings.Join(keys, ", ")
+}
@@ -0,0 +1,183 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-api-template/go-backend/modules/i18n"
+	"github.com/go-playground/validator/v10"
+	"github.com/rs/zerolog/log"
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+// FieldError is the error of a field of the request body
+//
+//	@description	Validation error of a field of the request body
+type FieldError struct {
+	// Field is the json name of the field, nested fields are separated by a dot
+	Field string `json:"field" example:"password"`
+	// Rule is the validation rule which failed
+	Rule string `json:"rule" example:"min"`
+	// Param is the parameter of the rule, if any
+	Param string `json:"param,omitempty" example:"8"`
+	// Message explains the error
+	Message string `json:"message" example:"password must be at least 8 characters long"`
+}
+
+// RegisterValidators configures the validator used to bind the request bodies
+// The errors are reported with the json names of the fields and the custom rules are added
+func RegisterValidators() {
+	validate, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		log.Fatal().Msg("The validator of the request bodies is not supported")
+		return
+	}
+
+	validate.RegisterTagNameFunc(structFieldName)
+
+	// confirms checks that the field is equal to another field given by its json name,
+	// e.g. the password confirmation and the password
+	if err := validate.RegisterValidation("confirms", confirms); err != nil {
+		log.Fatal().Err(err).Msg("Registering the confirms validator")
+	}
+
+	// locale checks that the field is a supported locale
+	if err := validate.RegisterValidation("locale", func(fl validator.FieldLevel) bool {
+		return i18n.Supported(fl.Field().String())
+	}); err != nil {
+		log.Fatal().Err(err).Msg("Registering the locale validator")
+	}
+}
+
+// Bind binds the json body of the request to the payload
+// The response is sent with the list of the invalid fields if the body is not valid,
+// the caller must stop handling the request when false is returned
+func Bind(ctx *gin.Context, payload any) bool {
+	err := ctx.ShouldBindJSON(payload)
+	if err == nil {
+		return true
+	}
+	SendBodyError(ctx, err)
+	return false
+}
+
+// SendBodyError sends the error of a request body which cannot be read or bound
+func SendBodyError(ctx *gin.Context, err error) {
+	var validationErrors validator.ValidationErrors
+	var typeError *json.UnmarshalTypeError
+	var maxBytesError *http.MaxBytesError
+	switch {
+	case errors.As(err, &maxBytesError):
+		SendBodyTooLarge(ctx, maxBytesError.Limit)
+	case errors.As(err, &validationErrors):
+		locale := i18n.Locale(ctx)
+		fields := make([]FieldError, len(validationErrors))
+		for i, fe := range validationErrors {
+			fields[i] = newFieldError(locale, fe)
+		}
+		Ctx(ctx).BadRequest().
+			WithCode(CodeValidationFailed).
+			WithDescription("The request body is not valid").
+			WithFieldErrors(fields).
+			Send()
+	case errors.As(err, &typeError):
+		Ctx(ctx).BadRequest().
+			WithCode(CodeValidationFailed).
+			WithDescription("The request body is not valid").
+			WithFieldErrors([]FieldError{{
+				Field: typeError.Field,
+				Rule:  "type",
+				Param: typeError.Type.String(),
+				Message: i18n.Translate(i18n.Locale(ctx), "{field} must be a {param}",
+					i18n.Params{"field": typeError.Field, "param": typeError.Type.String()}),
+			}}).
+			Send()
+	default:
+		Ctx(ctx).BadRequest().WithCode(CodeInvalidBody).WithError(err).Send()
+	}
+}
+
+// SendBodyTooLarge sends the error of a request body larger than the limit
+func SendBodyTooLarge(ctx *gin.Context, limit int64) {
+	Ctx(ctx).RequestEntityTooLarge().
+		WithCode(CodeRequestTooLarge).
+		WithDescription(i18n.Translate(i18n.Locale(ctx), "The request body must not be larger than {limit} bytes",
+			i18n.Params{"limit": limit})).
+		Send()
+}
+
+// newFieldError translates the error of the validator in the locale
+func newFieldError(locale string, fe validator.FieldError) FieldError {
+	// The namespace starts with the name of the struct, which is not a field of the body
+	field := fe.Namespace()
+	if i := strings.Index(field, "."); i >= 0 {
+		field = field[i+1:]
+	}
+
+	return FieldError{
+		Field: field,
+		Rule:  fe.Tag(),
+		Param: fe.Param(),
+		Message: i18n.Translate(locale, fieldMessage(fe.Tag(), fe.Kind()), i18n.Params{
+			"field": field,
+			"param": strings.ReplaceAll(fe.Param(), " ", ", "),
+		}),
+	}
+}
+
+// confirms is the validator of the confirmation fields
+func confirms(fl validator.FieldLevel) bool {
+	parent := fl.Parent()
+	if parent.Kind() == reflect.Pointer {
+		parent = parent.Elem()
+	}
+	if parent.Kind() != reflect.Struct {
+		return false
+	}
+	for i := 0; i < parent.NumField(); i++ {
+		if structFieldName(parent.Type().Field(i)) == fl.Param() {
+			field := parent.Field(i)
+			return field.Kind() == fl.Field().Kind() && field.Interface() == fl.Field().Interface()
+		}
+	}
+	return false
+}
+
+// fieldMessage returns the message of a failed rule
+// The parameters {field} and {param} are replaced by the name of the field and the parameter of the rule
+func fieldMessage(rule string, kind reflect.Kind) string {
+	isString := kind == reflect.String
+	switch rule {
+	case "required":
+		return "{field} is required"
+	case "email":
+		return "{field} must be a valid email address"
+	case "uuid", "uuid4":
+		return "{field} must be a valid uuid"
+	case "url":
+		return "{field} must be a valid url"
+	case "min":
+		if isString {
+			return "{field} must be at least {param} characters long"
+		}
+		return "{field} must be at least {param}"
+	case "max":
+		if isString {
+			return "{field} must be at most {param} characters long"
+		}
+		return "{field} must be at most {param}"
+	case "len":
+		return "{field} must be {param} long"
+	case "oneof":
+		return "{field} must be one of {param}"
+	case "confirms":
+		return "{field} must match {param}"
+	case "locale":
+		return "{field} is not a supported locale"
+	default:
+		return "{field} is not valid"
+	}
+}
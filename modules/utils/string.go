@@ -1,8 +1,22 @@
 package utils
 
-import "github.com/thanhpk/randstr"
+import (
+	"github.com/thanhpk/randstr"
+	"regexp"
+	"strings"
+)
+
+// nonAlphanumeric matches every sequence of characters which is not allowed in a slug
+var nonAlphanumeric = regexp.MustCompile(`[^a-z0-9]+`)
 
 // GenerateRandomString generates a random string of the given length
 func GenerateRandomString(length int) string {
 	return randstr.String(length)
 }
+
+// Slugify converts a string into a slug which can be used in urls
+// Every sequence of non alphanumeric characters is replaced by a dash
+func Slugify(s string) string {
+	slug := nonAlphanumeric.ReplaceAllString(strings.ToLower(s), "-")
+	return strings.Trim(slug, "-")
+}
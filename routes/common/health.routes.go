@@ -4,7 +4,12 @@ import (
 	"fmt"
 	"github.com/gin-gonic/gin"
 	"github.com/go-api-template/go-backend/modules/config"
+	"github.com/go-api-template/go-backend/modules/health"
 	api "github.com/go-api-template/go-backend/modules/utils/api"
+	"github.com/rs/zerolog/log"
+	"net/http"
+	"slices"
+	"strings"
 )
 
 type HealthCheckRouteController struct {
@@ -21,4 +26,83 @@ func (r *HealthCheckRouteController) NewRoutes(rg *gin.RouterGroup) {
 			"message": message,
 		})
 	})
+
+	// Probes of Kubernetes, they answer in the format of the Kubernetes api server
+	// The liveness probe restarts the server, the readiness probe stops sending it requests
+	rg.GET("/livez", r.probe("livez", true))
+	rg.GET("/livez/:check", r.check(true))
+	rg.GET("/readyz", r.probe("readyz", false))
+	rg.GET("/readyz/:check", r.check(false))
+}
+
+// probe runs the checks of a probe
+// The checks are listed if one of them fails or if the verbose parameter is given,
+// they can be skipped with the exclude parameter, e.g. /readyz?exclude=smtp&exclude=mail-queue
+func (r *HealthCheckRouteController) probe(name string, liveness bool) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		_, verbose := ctx.GetQuery("verbose")
+		results := health.Run(ctx.Request.Context(), liveness, ctx.QueryArray("exclude"))
+
+		var body strings.Builder
+		failed := false
+		for _, result := range results {
+			if result.Err != nil {
+				failed = true
+				log.Ctx(ctx.Request.Context()).Warn().Err(result.Err).Str("probe", name).Str("check", result.Name).Msg("Health check failed")
+				fmt.Fprintf(&body, "[-]%s failed: %s\n", result.Name, r.reason(result.Err))
+			} else {
+				fmt.Fprintf(&body, "[+]%s ok\n", result.Name)
+			}
+		}
+		for _, excluded := range health.Names(liveness) {
+			if slices.Contains(ctx.QueryArray("exclude"), excluded) {
+				fmt.Fprintf(&body, "[+]%s excluded: ok\n", excluded)
+			}
+		}
+
+		switch {
+		case failed:
+			fmt.Fprintf(&body, "%s check failed\n", name)
+			r.send(ctx, http.StatusServiceUnavailable, body.String())
+		case verbose:
+			fmt.Fprintf(&body, "%s check passed\n", name)
+			r.send(ctx, http.StatusOK, body.String())
+		default:
+			r.send(ctx, http.StatusOK, "ok")
+		}
+	}
+}
+
+// check runs a single check of a probe
+func (r *HealthCheckRouteController) check(liveness bool) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		name := ctx.Param("check")
+		if !slices.Contains(health.Names(liveness), name) {
+			r.send(ctx, http.StatusNotFound, "404 page not found")
+			return
+		}
+		result, _ := health.RunOne(ctx.Request.Context(), name)
+		if result.Err != nil {
+			log.Ctx(ctx.Request.Context()).Warn().Err(result.Err).Str("check", name).Msg("Health check failed")
+			r.send(ctx, http.StatusServiceUnavailable, "internal server error: "+r.reason(result.Err))
+			return
+		}
+		r.send(ctx, http.StatusOK, "ok")
+	}
+}
+
+// reason returns why a check failed
+// The errors may reveal the infrastructure, they are only shown in debug mode
+func (r *HealthCheckRouteController) reason(err error) string {
+	if config.Config.App.Debug {
+		return err.Error()
+	}
+	return "reason withheld"
+}
+
+// send sends the result of a probe as plain text
+func (r *HealthCheckRouteController) send(ctx *gin.Context, status int, body string) {
+	ctx.Header("Cache-Control", "no-store")
+	ctx.Header("X-Content-Type-Options", "nosniff")
+	ctx.String(status, body)
 }
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"github.com/gin-gonic/gin"
 	"github.com/go-api-template/go-backend/modules/config"
+	"github.com/go-api-template/go-backend/modules/middlewares"
 	api "github.com/go-api-template/go-backend/modules/utils/api"
 )
 
@@ -15,7 +16,8 @@ func NewStatusRoutesController() StatusRouteController {
 }
 
 func (r *StatusRouteController) NewRoutes(rg *gin.RouterGroup) {
-	rg.GET("/status", func(ctx *gin.Context) {
+	rg.GET("/status", middlewares.CacheResponses(), func(ctx *gin.Context) {
+		ctx.Header("Cache-Control", "public, max-age=60")
 		api.Ctx(ctx).Ok().SendRaw(gin.H{
 			"welcome":        fmt.Sprintf("Welcome to to go-api-template/%s", config.Config.App.Name),
 			"version":        config.Config.App.Version,
@@ -0,0 +1,39 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/go-api-template/go-backend/controllers"
+	"github.com/go-api-template/go-backend/modules/middlewares"
+)
+
+type InvitationRoutesController struct {
+	invitationController controllers.InvitationController
+}
+
+func NewInvitationRoutesController(invitationController controllers.InvitationController) InvitationRoutesController {
+	return InvitationRoutesController{invitationController}
+}
+
+func (r *InvitationRoutesController) NewRoutes(rg *gin.RouterGroup) {
+	// invitations routes for the admins of the organization
+	organizationAdmins := rg.Group("organizations/:"+middlewares.ParamOrganization+"/invitations").
+		Use(middlewares.VerifiedUser(), middlewares.OrganizationAdmin())
+	organizationAdmins.GET("", r.invitationController.List)
+	organizationAdmins.POST("", r.invitationController.Create)
+	organizationAdmins.POST("/:invitation_id/resend", r.invitationController.Resend)
+	organizationAdmins.DELETE("/:invitation_id", r.invitationController.Revoke)
+
+	// invitations routes for authenticated and verified users
+	usersVerified := rg.Group("invitations").
+		Use(middlewares.VerifiedUser())
+	usersVerified.POST("/:token/accept", r.invitationController.Accept)
+}
+
+// NewPublicRoutes adds the routes of the invitations for the anonymous users
+// They must be mounted with the strict rate limit of the authentication routes,
+// which prevents guessing the tokens and abusing the sign up
+func (r *InvitationRoutesController) NewPublicRoutes(rg *gin.RouterGroup) {
+	invitations := rg.Group("invitations")
+	invitations.GET("/:token", r.invitationController.GetByToken)
+	invitations.POST("/:token/signup", r.invitationController.SignUp)
+}
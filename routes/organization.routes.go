@@ -0,0 +1,45 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/go-api-template/go-backend/controllers"
+	"github.com/go-api-template/go-backend/modules/middlewares"
+	"github.com/go-api-template/go-backend/services"
+)
+
+type OrganizationRoutesController struct {
+	organizationController controllers.OrganizationController
+}
+
+func NewOrganizationRoutesController(organizationController controllers.OrganizationController) OrganizationRoutesController {
+	return OrganizationRoutesController{organizationController}
+}
+
+func (r *OrganizationRoutesController) NewRoutes(rg *gin.RouterGroup) {
+	// organizations routes
+	organizations := rg.Group("organizations")
+
+	// organizations routes for authenticated and verified users
+	usersVerified := organizations.Group("").
+		Use(middlewares.VerifiedUser())
+	usersVerified.GET("/", r.organizationController.List)
+	usersVerified.POST("/", r.organizationController.Create)
+
+	// organization routes for the members of the organization
+	organizationMembers := organizations.Group("/:"+middlewares.ParamOrganization).
+		Use(middlewares.VerifiedUser(), middlewares.OrganizationMember())
+	organizationMembers.GET("", middlewares.CacheResponses(middlewares.CacheTagId(services.OrganizationCacheTag, middlewares.ParamOrganization)), r.organizationController.GetById)
+	organizationMembers.GET("/members", r.organizationController.ListMembers)
+
+	// organization routes for the admins of the organization
+	organizationAdmins := organizations.Group("/:"+middlewares.ParamOrganization).
+		Use(middlewares.VerifiedUser(), middlewares.OrganizationAdmin())
+	organizationAdmins.PATCH("", r.organizationController.Update)
+	organizationAdmins.PATCH("/members/:user_id", r.organizationController.UpdateMember)
+	organizationAdmins.DELETE("/members/:user_id", r.organizationController.DeleteMember)
+
+	// organization routes for the owners of the organization
+	organizationOwners := organizations.Group("/:"+middlewares.ParamOrganization).
+		Use(middlewares.VerifiedUser(), middlewares.OrganizationOwner())
+	organizationOwners.DELETE("", r.organizationController.Delete)
+}
@@ -5,8 +5,10 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/go-api-template/go-backend/controllers"
 	"github.com/go-api-template/go-backend/modules/config"
+	"github.com/go-api-template/go-backend/modules/middlewares"
 	common_routes "github.com/go-api-template/go-backend/routes/common"
 	"sync"
+	"time"
 )
 
 type Routes struct {
@@ -22,6 +24,21 @@ type Routes struct {
 	// Routes below are routes for the various controllers
 	AuthRoutes AuthRoutesController
 	UserRoutes UserRoutesController
+
+	OrganizationRoutes OrganizationRoutesController
+	InvitationRoutes   InvitationRoutesController
+
+	// RateLimits are the limits of the requests sent to the groups of routes
+	RateLimits RateLimits
+}
+
+// RateLimits holds the limit of each group of routes
+type RateLimits struct {
+	// Default limits the requests sent by each user to the api
+	Default middlewares.RateLimit
+	// Auth limits the requests sent by each ip address to the authentication routes,
+	// it is much stricter to slow down the guessing of the passwords
+	Auth middlewares.RateLimit
 }
 
 var (
@@ -48,6 +65,25 @@ func (r *Routes) initialize(c *controllers.Controllers) {
 	r.StatusRoutes = common_routes.NewStatusRoutesController()
 	r.AuthRoutes = NewAuthRoutesController(c.AuthController)
 	r.UserRoutes = NewUserRoutesController(c.UserController)
+	r.OrganizationRoutes = NewOrganizationRoutesController(c.OrganizationController)
+	r.InvitationRoutes = NewInvitationRoutesController(c.InvitationController)
+
+	// Initialize rate limits
+	limits := config.Config.RateLimit
+	r.RateLimits = RateLimits{
+		Default: middlewares.RateLimit{
+			Name:     "default",
+			Requests: limits.Default.Requests,
+			Period:   time.Duration(limits.Default.Period) * time.Second,
+			Key:      middlewares.RateLimitByUser,
+		},
+		Auth: middlewares.RateLimit{
+			Name:     "auth",
+			Requests: limits.Auth.Requests,
+			Period:   time.Duration(limits.Auth.Period) * time.Second,
+			Key:      middlewares.RateLimitByIP,
+		},
+	}
 }
 
 func (r *Routes) mountRoutes(gr *gin.Engine) {
@@ -68,6 +104,13 @@ func (r *Routes) mountRoutes(gr *gin.Engine) {
 	r.PingRoutes.NewRoutes(base)
 	r.HealthCheckRoutes.NewRoutes(base)
 	r.StatusRoutes.NewRoutes(base)
-	r.AuthRoutes.NewRoutes(base)
-	r.UserRoutes.NewRoutes(base)
+
+	// Routes declared here are rate limited
+	auth := base.Group("", middlewares.LimitRate(r.RateLimits.Auth))
+	r.AuthRoutes.NewRoutes(auth)
+	r.InvitationRoutes.NewPublicRoutes(auth)
+	limited := base.Group("", middlewares.LimitRate(r.RateLimits.Default))
+	r.UserRoutes.NewRoutes(limited)
+	r.OrganizationRoutes.NewRoutes(limited)
+	r.InvitationRoutes.NewRoutes(limited)
 }
@@ -4,6 +4,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/go-api-template/go-backend/controllers"
 	"github.com/go-api-template/go-backend/modules/middlewares"
+	"github.com/go-api-template/go-backend/services"
 )
 
 type UserRoutesController struct {
@@ -29,7 +30,7 @@ func (r *UserRoutesController) NewRoutes(rg *gin.RouterGroup) {
 	usersAdmin := users.Group("").
 		Use(middlewares.AdminUser())
 	usersAdmin.GET("/", r.userController.List)
-	usersAdmin.GET("/:id", r.userController.GetById)
+	usersAdmin.GET("/:id", middlewares.CacheResponses(middlewares.CacheTagId(services.UserCacheTag, "id")), r.userController.GetById)
 	usersAdmin.PATCH("/:id", r.userController.Update)
 	usersAdmin.DELETE("/:id", r.userController.Delete)
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"github.com/go-api-template/go-backend/models"
+	database_logger "github.com/go-api-template/go-backend/modules/logger/database"
 	"github.com/go-api-template/go-backend/modules/utils"
 	"github.com/jackc/pgx/v5/pgconn"
 	"gorm.io/gorm"
@@ -13,6 +14,8 @@ import (
 // AuthService is the interface that the service must implement
 // It declares the methods that the service must implement
 type AuthService interface {
+	WithContext(ctx context.Context) AuthService
+
 	SignUp(user *models.UserSignUp) (*models.User, error)
 }
 
@@ -31,6 +34,11 @@ func NewAuthService(ctx context.Context, gormDb *gorm.DB) AuthService {
 	return &AuthServiceImpl{ctx: ctx, gormDb: gormDb}
 }
 
+// WithContext returns a copy of the service bound to the context of a request
+func (s *AuthServiceImpl) WithContext(ctx context.Context) AuthService {
+	return &AuthServiceImpl{ctx: ctx, gormDb: s.gormDb.WithContext(database_logger.WithContext(ctx))}
+}
+
 // SignUp creates a new user
 func (s *AuthServiceImpl) SignUp(user *models.UserSignUp) (*models.User, error) {
 
@@ -0,0 +1,291 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/go-api-template/go-backend/models"
+	"github.com/go-api-template/go-backend/modules/config"
+	database_logger "github.com/go-api-template/go-backend/modules/logger/database"
+	"github.com/go-api-template/go-backend/modules/utils"
+	api "github.com/go-api-template/go-backend/modules/utils/api"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+	"strings"
+	"time"
+)
+
+// InvitationService is an interface for the InvitationServiceImpl
+// It declares the methods that the InvitationServiceImpl must implement
+type InvitationService interface {
+	WithContext(ctx context.Context) InvitationService
+
+	Create(organizationId uuid.UUID, invitedBy uuid.UUID, invitation *models.InvitationInput) (*models.Invitation, error)
+
+	FindAll(organizationId uuid.UUID, params api.Filter) ([]models.Invitation, int64, error)
+	FindById(organizationId uuid.UUID, id uuid.UUID) (*models.Invitation, error)
+	FindByToken(token string) (*models.Invitation, error)
+
+	Resend(organizationId uuid.UUID, id uuid.UUID) (*models.Invitation, error)
+	Revoke(organizationId uuid.UUID, id uuid.UUID) error
+	Accept(invitation *models.Invitation, userId uuid.UUID) (*models.Membership, error)
+	SignUp(invitation *models.Invitation, user *models.UserSignUp) (*models.User, *models.Membership, error)
+}
+
+// ErrInvitationAccepted is returned when the invitation has already been accepted
+var ErrInvitationAccepted = errors.New("invitation already accepted")
+
+// InvitationServiceImpl is the service for the invitations
+// It implements the InvitationService interface
+type InvitationServiceImpl struct {
+	ctx    context.Context
+	gormDb *gorm.DB
+}
+
+// InvitationServiceImpl implements the InvitationService interface
+var _ InvitationService = &InvitationServiceImpl{}
+
+func NewInvitationService(ctx context.Context, gormDb *gorm.DB) InvitationService {
+	return &InvitationServiceImpl{ctx: ctx, gormDb: gormDb}
+}
+
+// WithContext returns a copy of the service bound to the context of a request
+func (s *InvitationServiceImpl) WithContext(ctx context.Context) InvitationService {
+	return &InvitationServiceImpl{ctx: ctx, gormDb: s.gormDb.WithContext(database_logger.WithContext(ctx))}
+}
+
+// Create invites an email address to join an organization
+func (s *InvitationServiceImpl) Create(organizationId uuid.UUID, invitedBy uuid.UUID, invitation *models.InvitationInput) (*models.Invitation, error) {
+	if _, err := models.ParseOrganizationRole(invitation.Role.String()); err != nil {
+		return nil, err
+	}
+	email := strings.ToLower(invitation.Email)
+
+	// Check that the invitee is not already a member of the organization
+	var members int64
+	result := s.gormDb.Model(&models.Membership{}).
+		Scopes(models.ScopeOrganization(organizationId)).
+		Joins("JOIN users ON users.id = memberships.user_id").
+		Where("users.email = ?", email).
+		Count(&members)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if members > 0 {
+		return nil, errors.New("user is already a member of the organization")
+	}
+
+	// Check that there is no pending invitation for the same email address
+	var pending int64
+	result = s.gormDb.Model(&models.Invitation{}).
+		Scopes(models.ScopeOrganization(organizationId)).
+		Where("email = ? AND accepted_at IS NULL AND expires_at > ?", email, time.Now()).
+		Count(&pending)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if pending > 0 {
+		return nil, errors.New("invitation for that email already exist")
+	}
+
+	// Create a new invitation
+	newInvitation := &models.Invitation{
+		OrganizationID: organizationId,
+		Email:          email,
+		Role:           invitation.Role,
+		InvitedByID:    invitedBy,
+	}
+	s.renew(newInvitation)
+
+	// Add the new invitation to the database
+	if results := s.gormDb.Create(newInvitation); results.Error != nil {
+		var pgError *pgconn.PgError
+		if errors.As(results.Error, &pgError) && pgError.Code == "23505" {
+			return nil, errors.New("invitation for that email already exist")
+		}
+		return nil, results.Error
+	}
+
+	return s.FindById(organizationId, newInvitation.ID)
+}
+
+// FindAll finds a page of the invitations of an organization
+// and counts all the invitations matching the filter
+func (s *InvitationServiceImpl) FindAll(organizationId uuid.UUID, params api.Filter) ([]models.Invitation, int64, error) {
+	var total int64
+	err := s.gormDb.Model(&models.Invitation{}).
+		Scopes(models.ScopeOrganization(organizationId), params.Where).
+		Count(&total).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var invitations []models.Invitation
+	result := s.gormDb.Scopes(models.ScopeOrganization(organizationId), params.Apply).
+		Find(&invitations)
+
+	if result.Error != nil {
+		return nil, 0, result.Error
+	}
+	if result.RowsAffected > 0 {
+		return invitations, total, nil
+	}
+	return nil, total, nil
+}
+
+// FindById finds an invitation of an organization
+func (s *InvitationServiceImpl) FindById(organizationId uuid.UUID, id uuid.UUID) (*models.Invitation, error) {
+	var invitation models.Invitation
+	result := s.gormDb.Scopes(models.ScopeOrganization(organizationId)).
+		Preload("Organization").
+		Preload("InvitedBy").
+		Find(&invitation, "id = ?", id)
+
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected > 0 {
+		return &invitation, nil
+	}
+	return nil, nil
+}
+
+// FindByToken finds an invitation from the token sent by email
+func (s *InvitationServiceImpl) FindByToken(token string) (*models.Invitation, error) {
+	var invitation models.Invitation
+	result := s.gormDb.Preload("Organization").
+		Find(&invitation, "token = ?", token)
+
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected > 0 {
+		return &invitation, nil
+	}
+	return nil, nil
+}
+
+// Resend generates a new token and extends the expiry of a pending invitation
+func (s *InvitationServiceImpl) Resend(organizationId uuid.UUID, id uuid.UUID) (*models.Invitation, error) {
+	invitation, err := s.FindById(organizationId, id)
+	if err != nil {
+		return nil, err
+	}
+	if invitation == nil {
+		return nil, nil
+	}
+	if invitation.IsAccepted() {
+		return nil, errors.New("invitation already accepted")
+	}
+
+	// Renew the invitation
+	s.renew(invitation)
+	result := s.gormDb.Model(invitation).Select("token", "expires_at").Updates(invitation)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return invitation, nil
+}
+
+// Revoke deletes a pending invitation
+func (s *InvitationServiceImpl) Revoke(organizationId uuid.UUID, id uuid.UUID) error {
+	result := s.gormDb.Scopes(models.ScopeOrganization(organizationId)).
+		Where("accepted_at IS NULL").
+		Delete(&models.Invitation{}, "id = ?", id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected > 0 {
+		return nil
+	}
+	return errors.New("unknown invitation")
+}
+
+// Accept adds the user to the organization of the invitation
+func (s *InvitationServiceImpl) Accept(invitation *models.Invitation, userId uuid.UUID) (*models.Membership, error) {
+	var membership *models.Membership
+	err := s.gormDb.Transaction(func(tx *gorm.DB) error {
+		var err error
+		membership, err = s.accept(tx, invitation, userId)
+		return err
+	})
+	if err != nil {
+		return nil, acceptError(err)
+	}
+
+	return membership, nil
+}
+
+// SignUp creates a verified user and adds it to the organization of the invitation
+// Both are done in a single transaction, so a failure never leaves an account without its membership
+func (s *InvitationServiceImpl) SignUp(invitation *models.Invitation, user *models.UserSignUp) (*models.User, *models.Membership, error) {
+	newUser, err := newUser(user, true)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var membership *models.Membership
+	err = s.gormDb.Transaction(func(tx *gorm.DB) error {
+		if err := insertUser(tx, newUser); err != nil {
+			return err
+		}
+		membership, err = s.accept(tx, invitation, newUser.ID)
+		return err
+	})
+	if err != nil {
+		return nil, nil, acceptError(err)
+	}
+
+	return newUser, membership, nil
+}
+
+// accept marks the invitation as accepted and creates the membership within the transaction
+// The invitation is only updated while it is still pending, so it cannot be accepted twice
+func (s *InvitationServiceImpl) accept(tx *gorm.DB, invitation *models.Invitation, userId uuid.UUID) (*models.Membership, error) {
+	if invitation.IsExpired() {
+		return nil, errors.New("invitation expired")
+	}
+
+	// Mark the invitation as accepted
+	acceptedAt := sql.NullTime{Time: time.Now(), Valid: true}
+	result := tx.Model(&models.Invitation{}).
+		Where("id = ? AND accepted_at IS NULL", invitation.ID).
+		Update("accepted_at", acceptedAt)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, ErrInvitationAccepted
+	}
+	invitation.AcceptedAt = acceptedAt
+
+	// Create the membership
+	membership := &models.Membership{
+		UserID:         userId,
+		OrganizationID: invitation.OrganizationID,
+		Role:           invitation.Role,
+	}
+	if err := tx.Create(membership).Error; err != nil {
+		return nil, err
+	}
+
+	return membership, nil
+}
+
+// acceptError translates the database errors raised while accepting an invitation
+func acceptError(err error) error {
+	var pgError *pgconn.PgError
+	if errors.As(err, &pgError) && pgError.Code == "23505" {
+		return errors.New("user is already a member of the organization")
+	}
+	return err
+}
+
+// renew generates a new token and a new expiry date
+// The token is not base64 encoded because it is used as a path parameter
+func (s *InvitationServiceImpl) renew(invitation *models.Invitation) {
+	invitation.Token = utils.GenerateRandomString(48)
+	invitation.ExpiresAt = time.Now().Add(time.Duration(config.Config.Invitations.MaxAge) * time.Hour)
+}
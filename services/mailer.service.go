@@ -4,9 +4,9 @@ import (
 	"bytes"
 	"context"
 	"errors"
-	"fmt"
 	"github.com/go-api-template/go-backend/models"
 	"github.com/go-api-template/go-backend/modules/config"
+	"github.com/go-api-template/go-backend/modules/i18n"
 	"github.com/go-api-template/go-backend/modules/mailer"
 	"html/template"
 	"os"
@@ -16,8 +16,11 @@ import (
 // MailerService is an interface for the MailerServiceImpl
 // It declares the methods that the service must implement
 type MailerService interface {
-	SendVerificationToken(user *models.User) error
-	SendResetToken(user *models.User) error
+	SendVerificationToken(ctx context.Context, user *models.User) error
+	SendResetToken(ctx context.Context, user *models.User) error
+	SendInvitation(ctx context.Context, invitation *models.Invitation) error
+
+	Stop(ctx context.Context) error
 }
 
 // MailerServiceImpl is the service for mail
@@ -34,6 +37,8 @@ type TplName string
 const (
 	mailAuthVerify TplName = "auth/verify"
 	mailAuthReset  TplName = "auth/reset"
+
+	mailOrganizationInvite TplName = "organization/invite"
 )
 
 const (
@@ -69,6 +74,12 @@ func NewMailerService(ctx context.Context) (MailerService, error) {
 	return s, nil
 }
 
+// Stop stops the mailer once the queued emails are sent
+// The emails which are not sent before the context is done are dropped
+func (s *MailerServiceImpl) Stop(ctx context.Context) error {
+	return s.mailer.Stop(ctx)
+}
+
 // loadTemplates loads the templates.
 func (s *MailerServiceImpl) loadTemplates() error {
 	if s.layoutTmpl != nil {
@@ -99,17 +110,22 @@ func (s *MailerServiceImpl) loadLayoutTemplate() (*template.Template, error) {
 }
 
 // generateFromTemplate generates a body from a template name and data
-func (s *MailerServiceImpl) generateFromTemplate(tplName TplName, data any) (body string, err error) {
+// The template of the locale, such as auth/verify.fr.gohtml, is used if it exists
+func (s *MailerServiceImpl) generateFromTemplate(tplName TplName, locale string, data map[string]any) (body string, err error) {
 	// Check if the layout template has been loaded
 	if s.layoutTmpl == nil {
 		return "", errors.New("layout template not loaded")
 	}
 
-	// Check if template file exists
-	tplFile := filepath.Clean(mailTemplatesPath + "/" + string(tplName) + ".gohtml")
+	// Check if template file exists, in the locale first
+	tplFile := filepath.Clean(mailTemplatesPath + "/" + string(tplName) + "." + locale + ".gohtml")
 	if _, err := os.Stat(tplFile); os.IsNotExist(err) {
-		return "", err
+		tplFile = filepath.Clean(mailTemplatesPath + "/" + string(tplName) + ".gohtml")
+		if _, err := os.Stat(tplFile); os.IsNotExist(err) {
+			return "", err
+		}
 	}
+	data["Locale"] = locale
 
 	// Create the template
 	tpl, err := template.Must(s.layoutTmpl.Clone()).ParseFiles(tplFile)
@@ -125,59 +141,123 @@ func (s *MailerServiceImpl) generateFromTemplate(tplName TplName, data any) (bod
 }
 
 // SendVerificationToken sends a verification token to the user
-func (s *MailerServiceImpl) SendVerificationToken(user *models.User) error {
+func (s *MailerServiceImpl) SendVerificationToken(ctx context.Context, user *models.User) error {
 	if user.VerificationToken == "" {
 		return errors.New("verification token is empty")
 	}
 
+	// The email is written in the language of the user
+	locale := userLocale(user)
+	subject := i18n.Translate(locale, "Your verification code {app_name}", i18n.Params{"app_name": config.Config.App.Name})
+
 	// data to be passed to the template
 	data := map[string]any{
-		"Title":     fmt.Sprintf("Your verification code %s", config.Config.App.Name),
+		"Title":     subject,
 		"AppUrl":    config.Config.Server.Url,
 		"AppName":   config.Config.App.Name,
 		"VerifyUrl": config.Config.Client.Url + "/auth/verify-email?key=" + user.VerificationToken,
 	}
 
 	// Generate the email body from the template
-	content, err := s.generateFromTemplate(mailAuthVerify, data)
+	content, err := s.generateFromTemplate(mailAuthVerify, locale, data)
 	if err != nil {
 		return err
 	}
 
 	// Prepare the email
-	message := mailer.NewMessage(user.Email, fmt.Sprintf("Your verification code %s", config.Config.App.Name), content)
+	message := mailer.NewMessage(user.Email, subject, content)
 
-	// Send the email
-	s.mailer.SendMail(&message)
-
-	return nil
+	// Queue the email, it is sent in background
+	return s.mailer.SendMail(ctx, &message)
 }
 
 // SendResetToken sends a reset token to the user
-func (s *MailerServiceImpl) SendResetToken(user *models.User) error {
+func (s *MailerServiceImpl) SendResetToken(ctx context.Context, user *models.User) error {
 	if user.ResetToken == "" {
 		return errors.New("reset token is empty")
 	}
 
+	// The email is written in the language of the user
+	locale := userLocale(user)
+	subject := i18n.Translate(locale, "Reset your {app_name} password", i18n.Params{"app_name": config.Config.App.Name})
+
 	// data to be passed to the template
 	data := map[string]any{
-		"Title":    fmt.Sprintf("Reset your %s password", config.Config.App.Name),
+		"Title":    subject,
 		"AppUrl":   config.Config.Server.Url,
 		"AppName":  config.Config.App.Name,
 		"ResetUrl": config.Config.Client.Url + "/auth/reset-password?key=" + user.ResetToken,
 	}
 
 	// Generate the email body from the template
-	content, err := s.generateFromTemplate(mailAuthReset, data)
+	content, err := s.generateFromTemplate(mailAuthReset, locale, data)
 	if err != nil {
 		return err
 	}
 
 	// Prepare the email
-	message := mailer.NewMessage(user.Email, fmt.Sprintf("Reset your %s password", config.Config.App.Name), content)
+	message := mailer.NewMessage(user.Email, subject, content)
 
-	// Send the email
-	s.mailer.SendMail(&message)
+	// Queue the email, it is sent in background
+	return s.mailer.SendMail(ctx, &message)
+}
 
-	return nil
+// SendInvitation sends an invitation to join an organization
+// The organization and the user who sent the invitation must be loaded
+func (s *MailerServiceImpl) SendInvitation(ctx context.Context, invitation *models.Invitation) error {
+	if invitation.Token == "" {
+		return errors.New("invitation token is empty")
+	}
+	if invitation.Organization == nil {
+		return errors.New("invitation organization is not loaded")
+	}
+
+	// The invited user may have no account yet,
+	// the email is written in the language of the user who sent the invitation
+	locale := userLocale(invitation.InvitedBy)
+	subject := i18n.Translate(locale, "Join {organization} on {app_name}", i18n.Params{
+		"organization": invitation.Organization.Name,
+		"app_name":     config.Config.App.Name,
+	})
+
+	// Name of the user who sent the invitation
+	invitedBy := i18n.Translate(locale, "Someone", nil)
+	if invitation.InvitedBy != nil {
+		invitedBy = invitation.InvitedBy.Email
+		if invitation.InvitedBy.Name != "" {
+			invitedBy = invitation.InvitedBy.Name
+		}
+	}
+
+	// data to be passed to the template
+	data := map[string]any{
+		"Title":            subject,
+		"AppUrl":           config.Config.Server.Url,
+		"AppName":          config.Config.App.Name,
+		"OrganizationName": invitation.Organization.Name,
+		"InvitedBy":        invitedBy,
+		"Role":             i18n.Translate(locale, invitation.Role.String(), nil),
+		"ExpiresAt":        invitation.ExpiresAt.Format(i18n.Translate(locale, "2006-01-02 15:04 MST", nil)),
+		"InviteUrl":        config.Config.Client.Url + "/invitations/accept?key=" + invitation.Token,
+	}
+
+	// Generate the email body from the template
+	content, err := s.generateFromTemplate(mailOrganizationInvite, locale, data)
+	if err != nil {
+		return err
+	}
+
+	// Prepare the email
+	message := mailer.NewMessage(invitation.Email, subject, content)
+
+	// Queue the email, it is sent in background
+	return s.mailer.SendMail(ctx, &message)
+}
+
+// userLocale returns the locale of the emails sent to a user
+func userLocale(user *models.User) string {
+	if user != nil && user.Locale != "" {
+		return user.Locale
+	}
+	return i18n.DefaultLocale()
 }
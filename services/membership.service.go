@@ -0,0 +1,215 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"github.com/go-api-template/go-backend/models"
+	database_logger "github.com/go-api-template/go-backend/modules/logger/database"
+	api "github.com/go-api-template/go-backend/modules/utils/api"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrLastOwner is returned when the last owner of an organization would leave it
+var ErrLastOwner = errors.New("an organization must keep at least one owner")
+
+// MembershipService is an interface for the MembershipServiceImpl
+// It declares the methods that the MembershipServiceImpl must implement
+// Every method is scoped to an organization
+type MembershipService interface {
+	WithContext(ctx context.Context) MembershipService
+
+	Create(organizationId uuid.UUID, userId uuid.UUID, role models.OrganizationRole) (*models.Membership, error)
+
+	FindAll(organizationId uuid.UUID, params api.Filter) ([]models.Membership, int64, error)
+	FindByUser(organizationId uuid.UUID, userId uuid.UUID) (*models.Membership, error)
+
+	UpdateRole(organizationId uuid.UUID, userId uuid.UUID, role models.OrganizationRole) (*models.Membership, error)
+	Delete(organizationId uuid.UUID, userId uuid.UUID) error
+}
+
+// MembershipServiceImpl is the service for the memberships
+// It implements the MembershipService interface
+type MembershipServiceImpl struct {
+	ctx    context.Context
+	gormDb *gorm.DB
+}
+
+// MembershipServiceImpl implements the MembershipService interface
+var _ MembershipService = &MembershipServiceImpl{}
+
+func NewMembershipService(ctx context.Context, gormDb *gorm.DB) MembershipService {
+	return &MembershipServiceImpl{ctx: ctx, gormDb: gormDb}
+}
+
+// WithContext returns a copy of the service bound to the context of a request
+func (s *MembershipServiceImpl) WithContext(ctx context.Context) MembershipService {
+	return &MembershipServiceImpl{ctx: ctx, gormDb: s.gormDb.WithContext(database_logger.WithContext(ctx))}
+}
+
+// Create adds a user to an organization
+func (s *MembershipServiceImpl) Create(organizationId uuid.UUID, userId uuid.UUID, role models.OrganizationRole) (*models.Membership, error) {
+	if _, err := models.ParseOrganizationRole(role.String()); err != nil {
+		return nil, err
+	}
+
+	// Create a new membership
+	newMembership := &models.Membership{
+		UserID:         userId,
+		OrganizationID: organizationId,
+		Role:           role,
+	}
+
+	// Add the new membership to the database
+	if results := s.gormDb.Create(newMembership); results.Error != nil {
+		var pgError *pgconn.PgError
+		if errors.As(results.Error, &pgError) && pgError.Code == "23505" {
+			return nil, errors.New("user is already a member of the organization")
+		}
+		return nil, results.Error
+	}
+
+	return newMembership, nil
+}
+
+// FindAll finds a page of the members of an organization
+// and counts all the members matching the filter
+func (s *MembershipServiceImpl) FindAll(organizationId uuid.UUID, params api.Filter) ([]models.Membership, int64, error) {
+	var total int64
+	err := s.gormDb.Model(&models.Membership{}).
+		Scopes(models.ScopeOrganization(organizationId), params.Where).
+		Count(&total).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var memberships []models.Membership
+	result := s.gormDb.Scopes(models.ScopeOrganization(organizationId), params.Apply).
+		Preload("User").
+		Find(&memberships)
+
+	if result.Error != nil {
+		return nil, 0, result.Error
+	}
+	if result.RowsAffected > 0 {
+		return memberships, total, nil
+	}
+	return nil, total, nil
+}
+
+// FindByUser finds the membership of a user in an organization
+func (s *MembershipServiceImpl) FindByUser(organizationId uuid.UUID, userId uuid.UUID) (*models.Membership, error) {
+	var membership models.Membership
+	result := s.gormDb.Scopes(models.ScopeOrganization(organizationId)).
+		Find(&membership, "user_id = ?", userId)
+
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected > 0 {
+		return &membership, nil
+	}
+	return nil, nil
+}
+
+// UpdateRole changes the role of a member
+// An organization must always keep at least one owner
+func (s *MembershipServiceImpl) UpdateRole(organizationId uuid.UUID, userId uuid.UUID, role models.OrganizationRole) (*models.Membership, error) {
+	if _, err := models.ParseOrganizationRole(role.String()); err != nil {
+		return nil, err
+	}
+
+	err := s.gormDb.Transaction(func(tx *gorm.DB) error {
+		membership, err := s.findMembership(tx, organizationId, userId)
+		if err != nil {
+			return err
+		}
+		if membership.Role.IsOwner() && !role.IsOwner() {
+			if err := s.ensureAnotherOwner(tx, organizationId); err != nil {
+				return err
+			}
+		}
+
+		return tx.Model(membership).Update("role", role).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.FindByUser(organizationId, userId)
+}
+
+// Delete removes a user from an organization
+// An organization must always keep at least one owner
+func (s *MembershipServiceImpl) Delete(organizationId uuid.UUID, userId uuid.UUID) error {
+	return s.gormDb.Transaction(func(tx *gorm.DB) error {
+		membership, err := s.findMembership(tx, organizationId, userId)
+		if err != nil {
+			return err
+		}
+		if membership.Role.IsOwner() {
+			if err := s.ensureAnotherOwner(tx, organizationId); err != nil {
+				return err
+			}
+		}
+
+		return tx.Delete(membership).Error
+	})
+}
+
+// findMembership finds the membership of a user within a transaction
+func (s *MembershipServiceImpl) findMembership(tx *gorm.DB, organizationId uuid.UUID, userId uuid.UUID) (*models.Membership, error) {
+	var membership models.Membership
+	result := tx.Scopes(models.ScopeOrganization(organizationId)).
+		Find(&membership, "user_id = ?", userId)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, errors.New("unknown member")
+	}
+	return &membership, nil
+}
+
+// ensureNotLastOwner returns ErrLastOwner if the user is the only owner of one of its organizations
+// The owners of these organizations are locked until the end of the transaction,
+// so two owners cannot leave the same organization at the same time
+func ensureNotLastOwner(tx *gorm.DB, userId uuid.UUID) error {
+	var owners []models.Membership
+	result := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("role = ? AND organization_id IN (?)", models.OrganizationRoleOwner,
+			tx.Model(&models.Membership{}).Select("organization_id").Where("user_id = ?", userId)).
+		Find(&owners)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	count := map[uuid.UUID]int{}
+	for _, owner := range owners {
+		count[owner.OrganizationID]++
+	}
+	for _, owner := range owners {
+		if owner.UserID == userId && count[owner.OrganizationID] <= 1 {
+			return ErrLastOwner
+		}
+	}
+	return nil
+}
+
+// ensureAnotherOwner returns an error if the organization has a single owner
+func (s *MembershipServiceImpl) ensureAnotherOwner(tx *gorm.DB, organizationId uuid.UUID) error {
+	var owners int64
+	result := tx.Model(&models.Membership{}).
+		Scopes(models.ScopeOrganization(organizationId)).
+		Where("role = ?", models.OrganizationRoleOwner).
+		Count(&owners)
+	if result.Error != nil {
+		return result.Error
+	}
+	if owners <= 1 {
+		return ErrLastOwner
+	}
+	return nil
+}
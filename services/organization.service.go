@@ -0,0 +1,222 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"github.com/go-api-template/go-backend/models"
+	"github.com/go-api-template/go-backend/modules/cache"
+	database_logger "github.com/go-api-template/go-backend/modules/logger/database"
+	"github.com/go-api-template/go-backend/modules/utils"
+	api "github.com/go-api-template/go-backend/modules/utils/api"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
+	"strings"
+)
+
+// OrganizationService is an interface for the OrganizationServiceImpl
+// It declares the methods that the OrganizationServiceImpl must implement
+type OrganizationService interface {
+	WithContext(ctx context.Context) OrganizationService
+
+	Create(owner *models.User, organization *models.OrganizationInput) (*models.Organization, error)
+
+	FindAll(params api.Filter) ([]models.Organization, int64, error)
+	FindAllByUser(userId uuid.UUID, params api.Filter) ([]models.Organization, int64, error)
+	FindById(id uuid.UUID) (*models.Organization, error)
+	FindBySlug(slug string) (*models.Organization, error)
+
+	Update(id uuid.UUID, organization *models.Organization) (*models.Organization, error)
+	Delete(id uuid.UUID) error
+}
+
+// OrganizationServiceImpl is the service for the organization
+// It implements the OrganizationService interface
+type OrganizationServiceImpl struct {
+	ctx    context.Context
+	gormDb *gorm.DB
+	cache  *cache.Cache
+}
+
+// OrganizationServiceImpl implements the OrganizationService interface
+var _ OrganizationService = &OrganizationServiceImpl{}
+
+// The responses tagged with an organization are invalidated when it changes, the cache can be nil when there is no Redis
+func NewOrganizationService(ctx context.Context, gormDb *gorm.DB, cache *cache.Cache) OrganizationService {
+	return &OrganizationServiceImpl{ctx: ctx, gormDb: gormDb, cache: cache}
+}
+
+// WithContext returns a copy of the service bound to the context of a request
+func (s *OrganizationServiceImpl) WithContext(ctx context.Context) OrganizationService {
+	return &OrganizationServiceImpl{ctx: ctx, gormDb: s.gormDb.WithContext(database_logger.WithContext(ctx)), cache: s.cache}
+}
+
+// Create creates a new organization
+// The user creating the organization becomes its owner
+func (s *OrganizationServiceImpl) Create(owner *models.User, organization *models.OrganizationInput) (*models.Organization, error) {
+
+	// Create a new organization from the input
+	newOrganization := &models.Organization{
+		Name: strings.TrimSpace(organization.Name),
+		Slug: utils.Slugify(organization.Slug),
+	}
+	if newOrganization.Slug == "" {
+		newOrganization.Slug = utils.Slugify(newOrganization.Name)
+	}
+	if newOrganization.Slug == "" {
+		return nil, errors.New("organization slug cannot be empty")
+	}
+
+	// Add the organization and its owner to the database
+	err := s.gormDb.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(newOrganization).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.Membership{
+			UserID:         owner.ID,
+			OrganizationID: newOrganization.ID,
+			Role:           models.OrganizationRoleOwner,
+		}).Error
+	})
+	if err != nil {
+		var pgError *pgconn.PgError
+		if errors.As(err, &pgError) && pgError.Code == "23505" {
+			return nil, errors.New("organization with that slug already exist")
+		}
+		return nil, err
+	}
+
+	return newOrganization, nil
+}
+
+// FindAll finds a page of organizations and counts all the organizations matching the filter
+func (s *OrganizationServiceImpl) FindAll(params api.Filter) ([]models.Organization, int64, error) {
+	var total int64
+	if err := s.gormDb.Model(&models.Organization{}).Scopes(params.Where).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var organizations []models.Organization
+	result := s.gormDb.Scopes(params.Apply).Find(&organizations)
+
+	if result.Error != nil {
+		return nil, 0, result.Error
+	}
+	if result.RowsAffected > 0 {
+		return organizations, total, nil
+	}
+	return nil, total, nil
+}
+
+// FindAllByUser finds a page of the organizations the user is a member of
+// and counts all of them matching the filter
+func (s *OrganizationServiceImpl) FindAllByUser(userId uuid.UUID, params api.Filter) ([]models.Organization, int64, error) {
+	byUser := func(query *gorm.DB) *gorm.DB {
+		return query.
+			Joins("JOIN memberships ON memberships.organization_id = organizations.id").
+			Where("memberships.user_id = ?", userId)
+	}
+
+	var total int64
+	if err := s.gormDb.Model(&models.Organization{}).Scopes(byUser, params.Where).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var organizations []models.Organization
+	result := s.gormDb.Scopes(byUser, params.Apply).Find(&organizations)
+
+	if result.Error != nil {
+		return nil, 0, result.Error
+	}
+	if result.RowsAffected > 0 {
+		return organizations, total, nil
+	}
+	return nil, total, nil
+}
+
+func (s *OrganizationServiceImpl) FindById(id uuid.UUID) (*models.Organization, error) {
+	var organization models.Organization
+	result := s.gormDb.Find(&organization, "id = ?", id)
+
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected > 0 {
+		return &organization, nil
+	}
+	return nil, nil
+}
+
+func (s *OrganizationServiceImpl) FindBySlug(slug string) (*models.Organization, error) {
+	var organization models.Organization
+	result := s.gormDb.Find(&organization, "slug = ?", strings.ToLower(slug))
+
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected > 0 {
+		return &organization, nil
+	}
+	return nil, nil
+}
+
+func (s *OrganizationServiceImpl) Update(id uuid.UUID, organization *models.Organization) (*models.Organization, error) {
+	// Keep the slug usable in urls
+	organization.Slug = utils.Slugify(organization.Slug)
+	if organization.Slug == "" {
+		return nil, errors.New("organization slug cannot be empty")
+	}
+
+	// Update the organization
+	result := s.gormDb.Model(organization).Select("name", "slug").Where("id = ?", id).Updates(organization)
+	if result.Error != nil {
+		var pgError *pgconn.PgError
+		if errors.As(result.Error, &pgError) && pgError.Code == "23505" {
+			return nil, errors.New("organization with that slug already exist")
+		}
+		return nil, result.Error
+	}
+	s.uncache(id)
+	if result.RowsAffected > 0 {
+		return s.FindById(id)
+	}
+	return nil, nil
+}
+
+// Delete deletes the organization, its memberships and its invitations
+func (s *OrganizationServiceImpl) Delete(id uuid.UUID) error {
+	defer s.uncache(id)
+	return s.gormDb.Transaction(func(tx *gorm.DB) error {
+		// Remove the members and the invitations of the organization
+		if err := tx.Scopes(models.ScopeOrganization(id)).Delete(&models.Membership{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Scopes(models.ScopeOrganization(id)).Delete(&models.Invitation{}).Error; err != nil {
+			return err
+		}
+
+		// Delete the organization
+		result := tx.Delete(&models.Organization{}, "id = ?", id)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errors.New("unknown organization")
+		}
+		return nil
+	})
+}
+
+// uncache removes the responses tagged with an organization from the cache once it has been modified
+func (s *OrganizationServiceImpl) uncache(id uuid.UUID) {
+	if err := s.cache.Invalidate(s.ctx, OrganizationCacheTag(id)); err != nil {
+		log.Ctx(s.ctx).Warn().Err(err).Str("organization_id", id.String()).Msg("Removing the organization from the cache")
+	}
+}
+
+// OrganizationCacheTag returns the tag of the cached values which depend on an organization
+// They are invalidated when the organization is updated or deleted
+func OrganizationCacheTag(id uuid.UUID) string {
+	return "organization:" + id.String()
+}
@@ -0,0 +1,157 @@
+package services
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"github.com/go-api-template/go-backend/modules/config"
+	"github.com/rs/zerolog/log"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var (
+	ErrRegistrationClosed     = errors.New("registration is closed")
+	ErrRegistrationInviteOnly = errors.New("registration is only possible with an invitation")
+	ErrEmailDomainNotAllowed  = errors.New("email domain is not allowed to sign up")
+	ErrEmailDomainDenied      = errors.New("disposable email addresses are not allowed")
+	ErrInvalidEmail           = errors.New("invalid email address")
+)
+
+// RegistrationService is an interface for the RegistrationServiceImpl
+// It declares the methods that the RegistrationServiceImpl must implement
+type RegistrationService interface {
+	CanSignUp(email string) error
+	CanSignUpWithInvitation(email string) error
+}
+
+// RegistrationServiceImpl is the service which applies the registration policy
+// It implements the RegistrationService interface
+type RegistrationServiceImpl struct {
+	ctx           context.Context
+	mode          config.RegistrationMode
+	allowed       []string
+	deniedDomains map[string]struct{}
+}
+
+// RegistrationServiceImpl implements the RegistrationService interface
+var _ RegistrationService = &RegistrationServiceImpl{}
+
+// NewRegistrationService creates a new service applying the registration policy
+// The list of denied domains is loaded from the file defined in the config
+func NewRegistrationService(ctx context.Context) RegistrationService {
+	s := &RegistrationServiceImpl{
+		ctx:           ctx,
+		mode:          config.Config.Registration.Mode,
+		allowed:       config.Config.Registration.AllowedDomains,
+		deniedDomains: map[string]struct{}{},
+	}
+
+	// Load the denied domains
+	if err := s.loadDeniedDomains(config.Config.Registration.DeniedDomainsFile); err != nil {
+		log.Warn().Err(err).Msg("Cannot load the denied email domains")
+	}
+
+	return s
+}
+
+// loadDeniedDomains loads the denied domains from a file
+// There is one domain per line, empty lines and lines starting with # are ignored
+func (s *RegistrationServiceImpl) loadDeniedDomains(path string) error {
+	if path == "" {
+		return nil
+	}
+
+	file, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		return err
+	}
+	defer func(file *os.File) { _ = file.Close() }(file)
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.ToLower(strings.TrimSpace(scanner.Text()))
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		s.deniedDomains[line] = struct{}{}
+	}
+
+	return scanner.Err()
+}
+
+// CanSignUp checks if the email address can be used to sign up without an invitation
+func (s *RegistrationServiceImpl) CanSignUp(email string) error {
+	switch {
+	case s.mode.IsClosed():
+		return ErrRegistrationClosed
+	case s.mode.IsInviteOnly():
+		return ErrRegistrationInviteOnly
+	}
+
+	domain, err := emailDomain(email)
+	if err != nil {
+		return err
+	}
+	if s.isDenied(domain) {
+		return ErrEmailDomainDenied
+	}
+	if s.mode.IsDomainRestricted() && !s.isAllowed(domain) {
+		return ErrEmailDomainNotAllowed
+	}
+
+	return nil
+}
+
+// CanSignUpWithInvitation checks if the invited email address can be used to sign up
+// The invitation bypasses the invite-only and domain-restricted modes
+func (s *RegistrationServiceImpl) CanSignUpWithInvitation(email string) error {
+	if s.mode.IsClosed() {
+		return ErrRegistrationClosed
+	}
+
+	domain, err := emailDomain(email)
+	if err != nil {
+		return err
+	}
+	if s.isDenied(domain) {
+		return ErrEmailDomainDenied
+	}
+
+	return nil
+}
+
+// isAllowed checks if the domain, or one of its parent domains, is allowed
+func (s *RegistrationServiceImpl) isAllowed(domain string) bool {
+	for _, allowed := range s.allowed {
+		if domain == allowed || strings.HasSuffix(domain, "."+allowed) {
+			return true
+		}
+	}
+	return false
+}
+
+// isDenied checks if the domain, or one of its parent domains, is denied
+func (s *RegistrationServiceImpl) isDenied(domain string) bool {
+	for d := domain; d != ""; {
+		if _, ok := s.deniedDomains[d]; ok {
+			return true
+		}
+		i := strings.Index(d, ".")
+		if i < 0 {
+			break
+		}
+		d = d[i+1:]
+	}
+	return false
+}
+
+// emailDomain returns the lower case domain of an email address
+func emailDomain(email string) (string, error) {
+	i := strings.LastIndex(email, "@")
+	if i < 0 || i == len(email)-1 {
+		return "", ErrInvalidEmail
+	}
+	return strings.ToLower(strings.TrimSpace(email[i+1:])), nil
+}
@@ -3,6 +3,7 @@ package services
 import (
 	"context"
 	"database/sql"
+	"github.com/go-api-template/go-backend/modules/cache"
 	"github.com/go-redis/redis/v8"
 	"gorm.io/gorm"
 	"sync"
@@ -15,6 +16,12 @@ type Services struct {
 	MailService MailerService
 	AuthService AuthService
 	UserService UserService
+
+	RegistrationService RegistrationService
+
+	OrganizationService OrganizationService
+	MembershipService   MembershipService
+	InvitationService   InvitationService
 }
 
 var (
@@ -37,5 +44,9 @@ func NewServices(ctx context.Context, gorm *gorm.DB, sql *sql.DB, redis *redis.C
 func (s *Services) initialize(ctx context.Context, gorm *gorm.DB, sql *sql.DB, redis *redis.Client) {
 	s.MailService, _ = NewMailerService(ctx)
 	s.AuthService = NewAuthService(ctx, gorm)
-	s.UserService = NewUserService(ctx, gorm)
+	s.UserService = NewUserService(ctx, gorm, cache.NewCache(redis))
+	s.RegistrationService = NewRegistrationService(ctx)
+	s.OrganizationService = NewOrganizationService(ctx, gorm, cache.NewCache(redis))
+	s.MembershipService = NewMembershipService(ctx, gorm)
+	s.InvitationService = NewInvitationService(ctx, gorm)
 }
@@ -4,45 +4,134 @@ import (
 	"context"
 	"errors"
 	"github.com/go-api-template/go-backend/models"
+	"github.com/go-api-template/go-backend/modules/cache"
+	database_logger "github.com/go-api-template/go-backend/modules/logger/database"
 	"github.com/go-api-template/go-backend/modules/utils"
 	api "github.com/go-api-template/go-backend/modules/utils/api"
 	"github.com/go-faker/faker/v4"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/rs/zerolog/log"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"strings"
+	"time"
 )
 
 // UserService is an interface for the UserServiceImpl
 // It declares the methods that the UserServiceImpl must implement
 type UserService interface {
+	WithContext(ctx context.Context) UserService
+
 	Create(user *models.UserSignUp) (*models.User, error)
+	CreateVerified(user *models.UserSignUp) (*models.User, error)
+	CreateFirstAdmin(user *models.UserSignUp) (*models.User, error)
 
-	FindAll(params api.Filter) ([]models.User, error)
+	FindAll(params api.Filter, selection api.Selection) ([]models.User, int64, error)
 	FindById(id uuid.UUID) (*models.User, error)
+	FindByIdWithSelection(id uuid.UUID, selection api.Selection) (*models.User, error)
 	FindByEmail(email string) (*models.User, error)
 	FindByVerificationToken(verificationToken string) (*models.User, error)
 	FindByResetPasswordToken(resetPasswordToken string) (*models.User, error)
+	CountByRole(role models.Role) (int64, error)
 
 	Update(id uuid.UUID, user *models.User) (*models.User, error)
+	UpdateIfUnmodified(id uuid.UUID, user *models.User, updatedAt time.Time) (*models.User, error)
 	Delete(id uuid.UUID) error
+	DeleteIfUnmodified(id uuid.UUID, updatedAt time.Time) error
 }
 
+// ErrUserModified is returned when a user has been modified since the version given by the client
+var ErrUserModified = errors.New("user has been modified")
+
+// ErrAdminExists is returned when the first platform admin is created twice
+var ErrAdminExists = errors.New("a platform admin already exists")
+
+// adminLockId is the key of the postgres advisory lock
+// which prevents two bootstraps from creating the first platform admin at the same time
+const adminLockId = 7_342_159_005
+
 // UserServiceImpl is the service for the user
 // It implements the UserService interface
 type UserServiceImpl struct {
 	ctx    context.Context
 	gormDb *gorm.DB
+	cache  *cache.Cache
 }
 
 // UserServiceImpl implements the UserService interface
 var _ UserService = &UserServiceImpl{}
 
-func NewUserService(ctx context.Context, gormDb *gorm.DB) UserService {
-	return &UserServiceImpl{ctx: ctx, gormDb: gormDb}
+// NewUserService creates the user service
+// The users found by id are cached, the cache can be nil when there is no Redis
+func NewUserService(ctx context.Context, gormDb *gorm.DB, cache *cache.Cache) UserService {
+	return &UserServiceImpl{ctx: ctx, gormDb: gormDb, cache: cache}
+}
+
+// WithContext returns a copy of the service bound to the context of a request
+// Its queries are canceled with the request, and traced and logged with it
+func (s *UserServiceImpl) WithContext(ctx context.Context) UserService {
+	return &UserServiceImpl{ctx: ctx, gormDb: s.gormDb.WithContext(database_logger.WithContext(ctx)), cache: s.cache}
 }
 
 func (s *UserServiceImpl) Create(user *models.UserSignUp) (*models.User, error) {
+	return s.create(user, false)
+}
+
+// CreateVerified creates a user whose email address is already verified
+// It is used when the email address has been proven by another way, such as an invitation
+func (s *UserServiceImpl) CreateVerified(user *models.UserSignUp) (*models.User, error) {
+	return s.create(user, true)
+}
+
+// CreateFirstAdmin creates the first platform admin with a verified email address
+// The check and the creation hold an advisory lock in a single transaction, so only one admin can be bootstrapped
+func (s *UserServiceImpl) CreateFirstAdmin(user *models.UserSignUp) (*models.User, error) {
+	admin, err := newUser(user, true)
+	if err != nil {
+		return nil, err
+	}
+	admin.Role = models.RoleAdmin
+
+	err = s.gormDb.Transaction(func(tx *gorm.DB) error {
+		// The lock is released at the end of the transaction
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", adminLockId).Error; err != nil {
+			return err
+		}
+
+		var admins int64
+		if err := tx.Model(&models.User{}).Where("role = ?", models.RoleAdmin).Count(&admins).Error; err != nil {
+			return err
+		}
+		if admins > 0 {
+			return ErrAdminExists
+		}
+
+		return insertUser(tx, admin)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return admin, nil
+}
+
+func (s *UserServiceImpl) create(user *models.UserSignUp, verified bool) (*models.User, error) {
+	newUser, err := newUser(user, verified)
+	if err != nil {
+		return nil, err
+	}
+
+	// Add the new user to the database
+	if err := insertUser(s.gormDb, newUser); err != nil {
+		return nil, err
+	}
+
+	return newUser, nil
+}
+
+// newUser builds a new user from its sign up input
+func newUser(user *models.UserSignUp, verified bool) (*models.User, error) {
 
 	// Hash the password
 	hashedPassword, err := utils.HashPassword(user.Password)
@@ -59,45 +148,68 @@ func (s *UserServiceImpl) Create(user *models.UserSignUp) (*models.User, error)
 		Email:             strings.ToLower(user.Email),
 		Password:          hashedPassword,
 		Role:              models.RoleUser,
+		Locale:            user.Locale,
 		VerificationToken: verificationCode,
 		Verified:          false,
 	}
 
-	// If this is the first user, set the role to admin
-	if s.gormDb.First(&models.User{}).RowsAffected == 0 {
-		newUser.Role = models.RoleAdmin
+	// The email address is already verified
+	if verified {
 		newUser.Verified = true
 		newUser.VerificationToken = ""
 	}
 
-	// Add the new user to the database
-	if results := s.gormDb.Create(&newUser); results.Error != nil {
+	return newUser, nil
+}
+
+// insertUser adds a new user to the database, possibly inside a transaction
+func insertUser(tx *gorm.DB, user *models.User) error {
+	if results := tx.Create(user); results.Error != nil {
 		var pgError *pgconn.PgError
 		if errors.As(results.Error, &pgError) && errors.Is(results.Error, pgError) && pgError.Code == "23505" {
-			return nil, errors.New("user with that email already exist")
+			return errors.New("user with that email already exist")
 		}
-		return nil, results.Error
+		return results.Error
 	}
-
-	return newUser, nil
+	return nil
 }
 
-func (s *UserServiceImpl) FindAll(params api.Filter) ([]models.User, error) {
+// FindAll finds a page of users and counts all the users matching the filter
+// Only the selected fields and relationships are read from the database
+func (s *UserServiceImpl) FindAll(params api.Filter, selection api.Selection) ([]models.User, int64, error) {
+	var total int64
+	if err := s.gormDb.Model(&models.User{}).Scopes(params.Where).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	// The sorted columns are needed by the cursors
+	selection = selection.WithColumns(params.SortColumns()...)
+
 	var users []models.User
-	result := s.gormDb.Scopes(params.Apply).Find(&users)
+	result := s.gormDb.Scopes(params.Apply, selection.Apply).Find(&users)
 
 	if result.Error != nil {
-		return nil, result.Error
+		return nil, 0, result.Error
 	}
 	if result.RowsAffected > 0 {
-		return users, nil
+		return users, total, nil
 	}
-	return nil, nil
+	return nil, total, nil
 }
 
+// FindById finds a user, it is read from the cache if possible
+// It is called by the authorizer on each authenticated request
 func (s *UserServiceImpl) FindById(id uuid.UUID) (*models.User, error) {
+	tags := []string{UserCacheTag(id)}
+	return cache.Remember(s.ctx, s.cache, userCacheKey(id), cache.TTL(), tags, func(ctx context.Context) (*models.User, error) {
+		return s.WithContext(ctx).FindByIdWithSelection(id, api.Selection{})
+	})
+}
+
+// FindByIdWithSelection finds a user reading only the selected fields and relationships
+func (s *UserServiceImpl) FindByIdWithSelection(id uuid.UUID, selection api.Selection) (*models.User, error) {
 	var user models.User
-	result := s.gormDb.Find(&user, "id = ?", id)
+	result := s.gormDb.Scopes(selection.Apply).Find(&user, "users.id = ?", id)
 
 	if result.Error != nil {
 		return nil, result.Error
@@ -147,7 +259,37 @@ func (s *UserServiceImpl) FindByResetPasswordToken(resetPasswordToken string) (*
 	return nil, nil
 }
 
+// CountByRole counts the users having the given platform role
+func (s *UserServiceImpl) CountByRole(role models.Role) (int64, error) {
+	var count int64
+	result := s.gormDb.Model(&models.User{}).Where("role = ?", role).Count(&count)
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	return count, nil
+}
+
 func (s *UserServiceImpl) Update(id uuid.UUID, user *models.User) (*models.User, error) {
+	return s.update(s.gormDb.Where("id = ?", id), id, user)
+}
+
+// UpdateIfUnmodified updates a user only if it has not been updated since updatedAt
+// It prevents two clients from overwriting each other, an error is returned if the user has been modified
+func (s *UserServiceImpl) UpdateIfUnmodified(id uuid.UUID, user *models.User, updatedAt time.Time) (*models.User, error) {
+	updated, err := s.update(s.gormDb.Where("id = ? AND updated_at = ?", id, updatedAt), id, user)
+	if err != nil || updated != nil {
+		return updated, err
+	}
+
+	// Nothing has been updated, either the user no longer exists or it has been modified
+	current, err := s.FindById(id)
+	if err != nil || current == nil {
+		return nil, err
+	}
+	return nil, ErrUserModified
+}
+
+func (s *UserServiceImpl) update(query *gorm.DB, id uuid.UUID, user *models.User) (*models.User, error) {
 	// Set the verification code if the user is not verified yet and the verification code is empty
 	if !user.Verified && user.VerificationToken == "" {
 		user.VerificationToken = utils.Encode(utils.GenerateRandomString(32))
@@ -160,49 +302,102 @@ func (s *UserServiceImpl) Update(id uuid.UUID, user *models.User) (*models.User,
 	// Update the user
 	// Use select("*") to update all fields, even if they are empty
 	// this prevent zero value fields from being updated
-	result := s.gormDb.Model(user).Select("*").Where("id = ?", id).Updates(user)
+	result := query.Model(user).Select("*").Updates(user)
 	if result.Error != nil {
 		return nil, result.Error
 	}
+	s.uncache(id)
 	if result.RowsAffected > 0 {
 		return s.FindById(id)
 	}
 	return nil, nil
 }
 
+// Delete anonymizes and deletes a user and its memberships
+// The user cannot be deleted while it is the last owner of an organization, ErrLastOwner is then returned
 func (s *UserServiceImpl) Delete(id uuid.UUID) error {
-	// Anonymize the user
-	user, err := s.anonymize(id)
-	if err != nil {
-		return err
-	}
-	if user == nil {
-		return errors.New("unknown user")
-	}
+	return s.delete(id, nil)
+}
 
-	// Delete the user from the database
-	result := s.gormDb.Delete(user)
-	if result.Error != nil {
-		return result.Error
-	}
-	if result.RowsAffected > 0 {
-		return nil
-	}
-	return errors.New("unknown user")
+// DeleteIfUnmodified deletes a user only if it has not been updated since updatedAt
+// It prevents a client from deleting a user it has not seen, an error is returned if the user has been modified
+func (s *UserServiceImpl) DeleteIfUnmodified(id uuid.UUID, updatedAt time.Time) error {
+	return s.delete(id, &updatedAt)
 }
 
-func (s *UserServiceImpl) anonymize(id uuid.UUID) (*models.User, error) {
-	// Get the user from the database
-	user, err := s.FindById(id)
-	if err != nil {
-		return nil, err
-	}
+func (s *UserServiceImpl) delete(id uuid.UUID, updatedAt *time.Time) error {
+	err := s.gormDb.Transaction(func(tx *gorm.DB) error {
+		// Lock the user until it is deleted
+		var user models.User
+		query := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", id)
+		if updatedAt != nil {
+			query = query.Where("updated_at = ?", *updatedAt)
+		}
+		result := query.Find(&user)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			// Either the user no longer exists or it has been modified
+			var count int64
+			if err := tx.Model(&models.User{}).Where("id = ?", id).Count(&count).Error; err != nil {
+				return err
+			}
+			if updatedAt != nil && count > 0 {
+				return ErrUserModified
+			}
+			return errors.New("unknown user")
+		}
+
+		// The organizations of the user must keep an owner
+		if err := ensureNotLastOwner(tx, id); err != nil {
+			return err
+		}
+
+		// Anonymize the user
+		anonymize(&user)
+		if err := tx.Model(&user).Select("*").Updates(&user).Error; err != nil {
+			return err
+		}
+
+		// Delete the user and its memberships
+		// The user is only soft deleted, so the memberships are not removed by the database
+		if err := tx.Where("user_id = ?", id).Delete(&models.Membership{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&user).Error
+	})
+	s.uncache(id)
+	return err
+}
 
-	// Anonymize the user
+// anonymize replaces the personal data of a user by fake data
+func anonymize(user *models.User) {
 	user.Email = faker.Email()
 	user.Name = faker.Name()
 	user.FirstName = faker.FirstName()
 	user.LastName = faker.LastName()
+}
+
+// uncache removes a user, and the responses tagged with it, from the cache once it has been modified
+// The key of the user is deleted explicitly, so a load in progress does not store the old user
+func (s *UserServiceImpl) uncache(id uuid.UUID) {
+	err := errors.Join(
+		s.cache.Delete(s.ctx, userCacheKey(id)),
+		s.cache.Invalidate(s.ctx, UserCacheTag(id)),
+	)
+	if err != nil {
+		log.Ctx(s.ctx).Warn().Err(err).Str("user_id", id.String()).Msg("Removing the user from the cache")
+	}
+}
+
+// userCacheKey returns the key of a user in the cache
+func userCacheKey(id uuid.UUID) string {
+	return "users:" + id.String()
+}
 
-	return s.Update(id, user)
+// UserCacheTag returns the tag of the cached values which depend on a user
+// They are invalidated when the user is updated or deleted
+func UserCacheTag(id uuid.UUID) string {
+	return "user:" + id.String()
 }
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	"github.com/go-api-template/go-backend/models"
+	"github.com/go-api-template/go-backend/modules/config"
+	"github.com/go-api-template/go-backend/modules/mailer"
+	"github.com/go-api-template/go-backend/modules/middlewares"
+	"github.com/go-api-template/go-backend/modules/tracing"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestTracing checks that the spans of a request form a single trace:
+// the server span continues the trace of the client, the queries and the mails sent in background are its children
+// It runs from the root of the project, where the configuration is read from app.env
+func TestTracing(t *testing.T) {
+	// Record the spans in memory
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	defer func() { _ = provider.Shutdown(context.Background()) }()
+
+	// The queries are traced but not sent to a database
+	gormDb, err := gorm.Open(postgres.Open("host=localhost"), &gorm.Config{
+		DryRun:               true,
+		DisableAutomaticPing: true,
+		Logger:               logger.Discard,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := gormDb.Use(tracing.GormPlugin{}); err != nil {
+		t.Fatal(err)
+	}
+
+	// The mails are sent to a closed port, the send fails at once but is traced
+	config.Config.Mailer.Smtp.Host = "127.0.0.1"
+	config.Config.Mailer.Smtp.Port = 1
+	m := mailer.NewMailer(context.Background())
+	m.Start()
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middlewares.Tracing())
+	router.GET("/users/:id", func(ctx *gin.Context) {
+		var user models.User
+		gormDb.WithContext(ctx.Request.Context()).Find(&user, "id = ?", ctx.Param("id"))
+		message := mailer.NewMessage("user@example.com", "Subject", "Body")
+		if err := m.SendMail(ctx.Request.Context(), &message); err != nil {
+			t.Error(err)
+		}
+		ctx.Status(http.StatusNoContent)
+	})
+
+	// Send a request continuing the trace of the client
+	const traceId = "4bf92f3577b34da6a3ce929d0e0e4736"
+	const parentId = "00f067aa0ba902b7"
+	request := httptest.NewRequest(http.MethodGet, "/users/42", nil)
+	request.Header.Set("traceparent", "00-"+traceId+"-"+parentId+"-01")
+	router.ServeHTTP(httptest.NewRecorder(), request)
+
+	// Wait for the mail sent in background
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := m.Stop(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	spans := exporter.GetSpans()
+	find := func(prefix string) tracetest.SpanStub {
+		t.Helper()
+		for _, span := range spans {
+			if strings.HasPrefix(span.Name, prefix) {
+				return span
+			}
+		}
+		t.Fatalf("no span %q in %d spans", prefix, len(spans))
+		return tracetest.SpanStub{}
+	}
+
+	server := find("GET /users/:id")
+	if got := server.SpanContext.TraceID().String(); got != traceId {
+		t.Errorf("the server span is in the trace %s, want %s", got, traceId)
+	}
+	if got := server.Parent.SpanID().String(); got != parentId || !server.Parent.IsRemote() {
+		t.Errorf("the parent of the server span is %s, want the remote span %s", got, parentId)
+	}
+	for _, name := range []string{"query", "smtp send"} {
+		child := find(name)
+		if child.SpanContext.TraceID() != server.SpanContext.TraceID() || child.Parent.SpanID() != server.SpanContext.SpanID() {
+			t.Errorf("the span %q is not a child of the server span", child.Name)
+		}
+	}
+}